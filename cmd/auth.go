@@ -0,0 +1,13 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Authentication actions",
+	Long:  `Log in to GitLab instances and check the credentials of the saved repos.`,
+}
+
+func init() {
+	RootCmd.AddCommand(authCmd)
+}
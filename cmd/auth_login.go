@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/howeyc/gopass"
+	"github.com/spf13/cobra"
+)
+
+// Login methods for 'auth login --method'.
+const (
+	loginWeb      = "web"
+	loginDevice   = "device"
+	loginPassword = "password"
+)
+
+var (
+	loginMethod       string
+	loginClientID     string
+	loginClientSecret string
+	loginRedirectURI  string
+	loginScopes       []string
+)
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to GitLab with OAuth2 and save the repo",
+	Long: `Log in to the GitLab instance of a repo with OAuth2 and save the repo
+in the config file, with the access and refresh tokens in the secret store.
+The access token is refreshed when it expires, so there's no need to log in
+again, unless the refresh token is revoked.
+
+The methods to log in are:
+
+  web       open GitLab in the browser to authorize the application (default)
+  device    enter a code in GitLab on any device (GitLab 17.2 or newer)
+  password  the user (-u) and password (-p), without opening GitLab
+
+The web and device methods need an OAuth2 application, added to GitLab under
+Settings > Applications, with the 'api' scope and, for the web method, the
+redirect URI ` + gitlab.DefaultRedirectURI + ` (or --redirect-uri). It is
+saved with the repo, so it only has to be given once. The password method
+uses the application if given.`,
+	Example: `  $ gitlab-cli auth login -r myrepo -U https://gitlab.com/user/repo --client-id <APP ID>
+  $ gitlab-cli auth login -r myrepo --method device
+  $ gitlab-cli auth login -r myrepo -U https://git.my-site.com/group/repo -u my_user`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := defaultRepo()
+		if name == "" {
+			fmt.Fprintf(os.Stderr, "error: no repo name given\n")
+			os.Exit(1)
+		}
+		r := LoadFromConfigNoInit(name)
+		r.Name = name
+		if err := login(cmd, r); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := r.SaveToConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := SaveViperConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		out := &loginOutput{Repo: r.Name, URL: r.URL.String()}
+		if u, _, err := r.Client.Users.CurrentUser(); err == nil {
+			out.User = u.Username
+		}
+		if tok := r.Client.OAuthToken(); tok != nil {
+			out.Scope = tok.Scope
+			if tok.ExpiresAt != nil {
+				out.ExpiresAt = tok.ExpiresAt.Format(time.RFC3339)
+			}
+		}
+		mustRender(out, func(w io.Writer) {
+			fmt.Fprintf(w, "Logged in to %s as %s, saved as '%s'\n", r.URL.Host, out.User, r.Name)
+		})
+	},
+}
+
+// loginOutput is the --output of 'auth login'.
+type loginOutput struct {
+	Repo      string `json:"repo"`
+	URL       string `json:"url"`
+	User      string `json:"user"`
+	Scope     string `json:"scope,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// login gets an OAuth2 token for the repo with the --method
+// and initializes it with the token.
+func login(cmd *cobra.Command, r *Repo) error {
+	flags := cmd.Flags()
+	if flags.Changed("client-id") {
+		r.OAuth.ClientID = loginClientID
+	}
+	if flags.Changed("client-secret") {
+		r.OAuth.ClientSecret = loginClientSecret
+	}
+	if flags.Changed("redirect-uri") {
+		r.OAuth.RedirectURI = loginRedirectURI
+	}
+	if flags.Changed("scopes") {
+		r.OAuth.Scopes = loginScopes
+	}
+	method := loginMethod
+	if !flags.Changed("method") && user != "" {
+		method = loginPassword
+	}
+	if method != loginPassword && r.OAuth.ClientID == "" {
+		return fmt.Errorf("no OAuth application, give its id with --client-id or log in with --user")
+	}
+
+	if err := r.parseURL(); err != nil {
+		return err
+	}
+	u, _ := r.instanceURL()
+	opts, err := r.options()
+	if err != nil {
+		return err
+	}
+	var tok *gitlab.OAuthToken
+	switch method {
+	case loginWeb:
+		tok, err = gitlab.AuthCodeFlow(&u, r.OAuth.app(), opts, func(authURL string) {
+			fmt.Fprintf(os.Stderr, "Open this URL in your browser to log in:\n\n  %s\n\n", authURL)
+			openBrowser(authURL)
+		})
+	case loginDevice:
+		tok, err = gitlab.DeviceFlow(&u, r.OAuth.app(), opts, func(code *gitlab.DeviceCode) {
+			fmt.Fprintf(os.Stderr, "Open %s and enter the code: %s\n", code.VerificationURI, code.UserCode)
+		})
+	case loginPassword:
+		if user == "" {
+			return fmt.Errorf("no user given, use --user")
+		}
+		if password == "" {
+			fmt.Fprint(os.Stderr, "Password: ")
+			pwd, _ := gopass.GetPasswdMasked()
+			password = string(pwd)
+		}
+		tok, err = gitlab.PasswordGrant(&u, r.OAuth.app(), user, password, opts)
+	default:
+		return fmt.Errorf("unknown login method '%s', must be one of: %s, %s, %s",
+			method, loginWeb, loginDevice, loginPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to log in: %v", err)
+	}
+
+	r.Token, r.TokenSecret = "", ""
+	r.oauthToken = tok
+	// the password was only for getting the token
+	user = ""
+	return r.initialize()
+}
+
+// openBrowser opens the url in the default browser, if it can.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err == nil {
+		go cmd.Wait()
+	}
+}
+
+func init() {
+	authCmd.AddCommand(authLoginCmd)
+
+	authLoginCmd.Flags().StringVar(&loginMethod, "method", loginWeb, "how to log in: web, device or password (default password with --user)")
+	authLoginCmd.Flags().StringVar(&loginClientID, "client-id", "", "application ID of the OAuth2 application")
+	authLoginCmd.Flags().StringVar(&loginClientSecret, "client-secret", "", "secret of the OAuth2 application, if it is confidential")
+	authLoginCmd.Flags().StringVar(&loginRedirectURI, "redirect-uri", "", "redirect URI of the OAuth2 application, on localhost (default "+gitlab.DefaultRedirectURI+")")
+	authLoginCmd.Flags().StringSliceVar(&loginScopes, "scopes", nil, "scopes to ask for (default api)")
+}
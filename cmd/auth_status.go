@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// expiresSoon is how long before a token expires 'auth status' warns.
+const expiresSoon = 7 * 24 * time.Hour
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check the credentials of the saved repos",
+	Long: `Check the credentials of every repo from the config file, or only of the
+repo given by -r or -U: log in, then show the user, the scopes and expiry
+of the token, the API version and whether the project is found.
+
+Exits with an error if the credentials of any repo don't work, the token
+has neither the 'api' nor the 'read_api' scope or the project is not found.
+A token with only 'read_api' is reported with a warning: it's enough for the
+commands that only read, like 'label list' or 'issue list', but not for the
+ones that make changes.`,
+	Example: `  $ gitlab-cli auth status
+  $ gitlab-cli auth status -r myrepo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var names []string
+		if repo != "" || viper.GetString("_url") != "" {
+			names = []string{repo}
+		} else {
+			for name := range viper.GetStringMap("repos") {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		if len(names) == 0 {
+			fmt.Fprintf(os.Stderr, "error: no repos in the config file, give one with -r or -U\n")
+			os.Exit(1)
+		}
+		statuses := make([]*authStatus, len(names))
+		broken := 0
+		for i, name := range names {
+			statuses[i] = checkAuth(name)
+			if statuses[i].Error != "" {
+				broken++
+			}
+		}
+		mustRender(statuses, func(out io.Writer) {
+			w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "REPO\tUSER\tTOKEN\tSCOPES\tEXPIRES\tAPI\tPROJECT\tSTATUS")
+			for _, s := range statuses {
+				status := "ok"
+				if s.Error != "" {
+					status = "error: " + s.Error
+				} else if s.Warning != "" {
+					status = "warning: " + s.Warning
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Repo, orDash(s.User), orDash(s.Token),
+					orDash(strings.Join(s.Scopes, ",")), orDash(s.ExpiresAt), orDash(s.APIVersion),
+					orDash(s.Project), status)
+			}
+			w.Flush()
+		})
+		if broken > 0 {
+			fmt.Fprintf(os.Stderr, "error: %d of %d repo(s) can't be used\n", broken, len(names))
+			os.Exit(1)
+		}
+	},
+}
+
+// authStatus is the status of the credentials of a repo.
+type authStatus struct {
+	Repo       string   `json:"repo"`
+	URL        string   `json:"url"`
+	User       string   `json:"user,omitempty"`
+	Token      string   `json:"token,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	APIVersion string   `json:"api_version,omitempty"`
+	Project    string   `json:"project,omitempty"`
+	Warning    string   `json:"warning,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// checkAuth logs in to the repo with the given name and returns
+// the status of its credentials, with the first error, if any.
+func checkAuth(name string) *authStatus {
+	s := &authStatus{Repo: name}
+	r, err := loadFromConfigNoInit(name)
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.URL = r.Url_
+	if name == "" {
+		s.Repo = r.Url_
+	}
+	if err := r.parseURL(); err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	if err := r.initializeClient(); err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.APIVersion = r.APIVersion
+
+	u, _, err := r.Client.Users.CurrentUser()
+	if err != nil {
+		s.Error = fmt.Sprintf("failed to log in: %v", err)
+		return s
+	}
+	s.User = u.Username
+
+	info, err := r.Client.TokenInfo()
+	if err != nil {
+		s.Error = fmt.Sprintf("failed to get the token info: %v", err)
+		return s
+	}
+	s.Token = info.Type
+	s.Scopes = info.Scopes
+	if info.ExpiresAt != nil {
+		s.ExpiresAt = info.ExpiresAt.Format(time.RFC3339)
+		if info.ExpiresAt.Sub(time.Now()) < expiresSoon && info.Type != "oauth" {
+			// OAuth tokens are refreshed, private ones have to be replaced
+			s.addWarning("the token expires soon")
+		}
+	}
+	switch {
+	case info.HasScope("api"):
+	case info.HasScope("read_api"):
+		// enough for the commands that only read, like 'label list'
+		s.addWarning("the token only has the 'read_api' scope, it can't make changes")
+	default:
+		s.Error = "the token doesn't have the 'api' or 'read_api' scope"
+		return s
+	}
+
+	_, project := r.instanceURL()
+	if !strings.Contains(project, "/") {
+		s.Error = "invalid or no repo path specified"
+		return s
+	}
+	if r.Project, err = r.project(); err != nil {
+		s.Error = fmt.Sprintf("failed to get GitLab project '%s': %v", project, err)
+		return s
+	}
+	s.Project = r.Project.PathWithNamespace
+	return s
+}
+
+// addWarning adds a warning to the status.
+func (s *authStatus) addWarning(warning string) {
+	if s.Warning != "" {
+		s.Warning += "; "
+	}
+	s.Warning += warning
+}
+
+// orDash returns s, or "-" for an empty table cell.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+}
@@ -8,20 +8,61 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
 )
 
 var configCatCmd = &cobra.Command{
 	Use:   "cat",
 	Short: "Print config file",
+	Long: `Print config file, with the tokens and the other secrets masked.
+Use --show-secrets to print them.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		b, err := ioutil.ReadFile(viper.ConfigFileUsed())
 		if err != nil {
 			os.Exit(1)
 		}
+		if !showSecrets {
+			var config yaml.MapSlice
+			if err := yaml.Unmarshal(b, &config); err != nil {
+				fmt.Fprintf(os.Stderr, "error: invalid config file: %v\n", err)
+				os.Exit(1)
+			}
+			if b, err = yaml.Marshal(maskConfig(config)); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 		fmt.Fprintln(os.Stdout, string(b))
 	},
 }
 
+// maskConfig masks the tokens, OAuth application secrets, header values
+// and proxy passwords anywhere in the config.
+func maskConfig(config yaml.MapSlice) yaml.MapSlice {
+	for i, item := range config {
+		switch value := item.Value.(type) {
+		case string:
+			switch item.Key {
+			case "token", "oauth_token", "client_secret":
+				config[i].Value = maskSecret(value)
+			case "proxy":
+				config[i].Value = maskURL(value)
+			}
+		case yaml.MapSlice:
+			if item.Key == "headers" {
+				for j, header := range value {
+					if s, ok := header.Value.(string); ok {
+						value[j].Value = maskSecret(s)
+					}
+				}
+			} else {
+				maskConfig(value)
+			}
+		}
+	}
+	return config
+}
+
 func init() {
 	configCmd.AddCommand(configCatCmd)
 }
@@ -0,0 +1,18 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var configHostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Config hosts actions",
+	Long: `Perform actions on the hosts from the config file.
+
+A host is a GitLab instance with the credentials and settings to connect to
+it, shared by all the repos on it. Repos refer to it with 'host' and the path
+of their project with 'project', instead of 'url'. Repos given with -U whose
+url is on a saved host use its credentials, unless given with -t or -u.`,
+}
+
+func init() {
+	configCmd.AddCommand(configHostCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configHostLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List hosts from config file",
+	Run: func(cmd *cobra.Command, args []string) {
+		var names []string
+		for name := range viper.GetStringMap("hosts") {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		hosts := make([]*Repo, len(names))
+		out := make([]*repoOutput, len(names))
+		for i, name := range names {
+			hosts[i] = LoadHostFromConfigNoInit(name)
+			out[i] = hosts[i].output()
+		}
+		mustRender(out, func(w io.Writer) {
+			for _, h := range hosts {
+				fmt.Fprintln(w, h.String())
+			}
+		})
+	},
+}
+
+func init() {
+	configHostCmd.AddCommand(configHostLsCmd)
+}
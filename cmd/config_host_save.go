@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configHostSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save host into the config file",
+	Example: `  $ gitlab-cli config host save mygitlab -U https://git.my-site.com -t <TOKEN>
+  $ gitlab-cli config repo save -r myrepo -U https://git.my-site.com/group/repo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "error: no host name given\n")
+			os.Exit(1)
+		}
+		h := LoadHostFromConfigNoInit(args[0])
+		if err := h.parseURL(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid host: %v\n", err)
+			os.Exit(1)
+		}
+		if err := h.initializeClient(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid host: %v\n", err)
+			os.Exit(1)
+		}
+		if _, _, err := h.Client.Users.CurrentUser(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid host: failed to log in: %v\n", err)
+			os.Exit(1)
+		}
+		if err := h.SaveToConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := SaveViperConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		mustRender(h.output(), nil)
+	},
+}
+
+func init() {
+	configHostCmd.AddCommand(configHostSaveCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var unsetDefaultRepo bool
+
+var configRepoDefaultCmd = &cobra.Command{
+	Use:   "default [<name>]",
+	Short: "Set the default repo",
+	Long: `Set the repo used when neither -r nor -U is given, or show it if no
+name is given.`,
+	Example: `  $ gitlab-cli config repo default myrepo
+  $ gitlab-cli config repo default --unset`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !unsetDefaultRepo {
+			name := viper.GetString("default_repo")
+			if name == "" {
+				fmt.Fprintf(os.Stderr, "error: no default repo\n")
+				os.Exit(1)
+			}
+			mustRender(&defaultRepoOutput{Name: name}, func(w io.Writer) {
+				fmt.Fprintln(w, name)
+			})
+			return
+		}
+		name := ""
+		if !unsetDefaultRepo {
+			name = args[0]
+			if !viper.IsSet("repos." + name) {
+				fmt.Fprintf(os.Stderr, "error: repo '%s' is not in the config file\n", name)
+				os.Exit(1)
+			}
+		}
+		viper.Set("default_repo", name)
+		if err := SaveViperConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// defaultRepoOutput is the --output of config repo default.
+type defaultRepoOutput struct {
+	Name string `json:"name"`
+}
+
+func init() {
+	configRepoCmd.AddCommand(configRepoDefaultCmd)
+	configRepoDefaultCmd.Flags().BoolVar(&unsetDefaultRepo, "unset", false, "unset the default repo")
+}
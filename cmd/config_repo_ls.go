@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -11,10 +13,22 @@ var configRepoLsCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List repositories from config file",
 	Run: func(cmd *cobra.Command, args []string) {
+		var names []string
 		for name, _ := range viper.GetStringMap("repos") {
-			r := LoadFromConfigNoInit(name)
-			fmt.Println(r.String())
+			names = append(names, name)
 		}
+		sort.Strings(names)
+		repos := make([]*Repo, len(names))
+		out := make([]*repoOutput, len(names))
+		for i, name := range names {
+			repos[i] = LoadFromConfigNoInit(name)
+			out[i] = repos[i].output()
+		}
+		mustRender(out, func(w io.Writer) {
+			for _, r := range repos {
+				fmt.Fprintln(w, r.String())
+			}
+		})
 	},
 }
 
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configRepoRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new name>",
+	Short: "Rename repo in the config file",
+	Long: `Rename a repo in the config file, moving its tokens in the secret store
+and updating the repo groups and the default repo.`,
+	Example: `  $ gitlab-cli config repo rename myrepo backend`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Fprintf(os.Stderr, "error: the repo name and the new name are required\n")
+			os.Exit(1)
+		}
+		if err := renameRepo(args[0], args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(messages(), "Renamed repo '%s' to '%s'\n", args[0], args[1])
+	},
+}
+
+// renameRepo renames a repo in the config file and saves it, then moves
+// its secrets. If moving them fails, the config file is saved with the
+// old name again.
+func renameRepo(oldName, newName string) error {
+	if !viper.IsSet("repos." + oldName) {
+		return fmt.Errorf("repo '%s' is not in the config file", oldName)
+	}
+	if viper.IsSet("repos." + newName) {
+		return fmt.Errorf("repo '%s' already exists", newName)
+	}
+	oldKey, newKey := "repos."+oldName, "repos."+newName
+	// the config before the rename, to go back to if it fails
+	repos, groups := viper.Get("repos"), viper.Get("repo_groups")
+	// the secrets to move, by old key, once the config is saved
+	moves := make(map[string]string)
+	var store secretStore
+	err := editConfig("repos", func(entries map[string]*repoMap) error {
+		rep := entries[oldName]
+		if len(ownSecrets(rep, oldKey)) > 0 {
+			var err error
+			if store, err = getSecretStore(); err != nil {
+				return err
+			}
+			if store == nil {
+				return fmt.Errorf("the tokens are in a secret store, but secret_store is '%s'", storePlain)
+			}
+			for _, key := range []*string{&rep.TokenSecret, &rep.OAuthTokenSecret} {
+				if !strings.HasPrefix(*key, oldKey+".") {
+					continue
+				}
+				renamed := newKey + strings.TrimPrefix(*key, oldKey)
+				moves[*key] = renamed
+				*key = renamed
+			}
+		}
+		delete(entries, oldName)
+		entries[newName] = rep
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	renameInRepoGroups(oldName, newName)
+	if viper.GetString("default_repo") == oldName {
+		viper.Set("default_repo", newName)
+	}
+	if err := SaveViperConfig(); err != nil {
+		return err
+	}
+	if err := moveSecrets(store, moves); err != nil {
+		// back to the old name, that the secrets are still under
+		viper.Set("repos", repos)
+		if groups != nil {
+			viper.Set("repo_groups", groups)
+		}
+		if viper.GetString("default_repo") == newName {
+			viper.Set("default_repo", oldName)
+		}
+		if rerr := SaveViperConfig(); rerr != nil {
+			return fmt.Errorf("%v, and failed to rename the repo back to '%s': %v", err, oldName, rerr)
+		}
+		return fmt.Errorf("%v, the repo was not renamed", err)
+	}
+	return nil
+}
+
+// moveSecrets moves the secrets from the old keys to the new ones, as
+// given by moves. If one fails, the ones moved so far are moved back.
+func moveSecrets(store secretStore, moves map[string]string) error {
+	var moved []string
+	for oldKey, newKey := range moves {
+		if err := moveSecret(store, oldKey, newKey); err != nil {
+			for _, key := range moved {
+				if merr := moveSecret(store, moves[key], key); merr != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to move '%s' back to '%s' in the secret store: %v\n", moves[key], key, merr)
+				}
+			}
+			return err
+		}
+		moved = append(moved, oldKey)
+	}
+	return nil
+}
+
+func init() {
+	configRepoCmd.AddCommand(configRepoRenameCmd)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+func TestRenameRepo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitlab-cli-rename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setenv(t, "HOME", dir)()
+	homedir.DisableCache = true
+	defer func() { homedir.DisableCache = false }()
+	secretStoreName = storeFile
+	defer func() { secretStoreName = "" }()
+	config := filepath.Join(dir, ".gitlab-cli.yaml")
+	viper.SetConfigFile(config)
+	defer viper.SetConfigFile("")
+
+	viper.Set("repos", map[string]interface{}{
+		"old": map[string]interface{}{
+			"url":          "https://gitlab.com/group/repo",
+			"token_secret": "repos.old.token",
+		},
+		"broken": map[string]interface{}{
+			"url":                "https://gitlab.com/group/other",
+			"token_secret":       "repos.broken.token",
+			"oauth_token_secret": "repos.broken.oauth_token",
+		},
+	})
+	viper.Set("repo_groups", map[string]interface{}{"all": []string{"old", "broken"}})
+	viper.Set("default_repo", "broken")
+	defer viper.Set("repos", map[string]interface{}{})
+	defer viper.Set("repo_groups", map[string]interface{}{})
+	defer viper.Set("default_repo", "")
+
+	store, err := newFileStore(secretsFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"repos.old.token", "repos.broken.token"} {
+		if err := store.Set(key, "secret"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// reload sets the config from the saved file, as the next command would
+	reload := func() {
+		v := viper.New()
+		v.SetConfigFile(config)
+		if err := v.ReadInConfig(); err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range []string{"repos", "repo_groups", "default_repo"} {
+			viper.Set(key, v.Get(key))
+		}
+	}
+
+	if err := renameRepo("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	reload()
+	if viper.IsSet("repos.old") || viper.GetString("repos.new.token_secret") != "repos.new.token" {
+		t.Errorf("expecting the repo renamed with its token, got %v", viper.Get("repos"))
+	}
+	if value, err := store.Get("repos.new.token"); err != nil || value != "secret" {
+		t.Errorf("expecting the token moved in the secret store, got %q, %v", value, err)
+	}
+	if _, err := store.Get("repos.old.token"); err == nil {
+		t.Error("expecting the old token to be removed from the secret store")
+	}
+	if groups := viper.GetStringMapStringSlice("repo_groups"); strings.Join(groups["all"], ",") != "new,broken" {
+		t.Errorf("expecting the repo renamed in the repo groups, got %v", groups)
+	}
+
+	// the oauth token is missing from the secret store
+	if err := renameRepo("broken", "fixed"); err == nil || !strings.Contains(err.Error(), "not renamed") {
+		t.Fatalf("expecting the rename to fail, got %v", err)
+	}
+	reload()
+	if !viper.IsSet("repos.broken") || viper.IsSet("repos.fixed") || viper.GetString("default_repo") != "broken" {
+		t.Errorf("expecting the saved config to be rolled back, got %v and default repo '%s'", viper.Get("repos"),
+			viper.GetString("default_repo"))
+	}
+	if groups := viper.GetStringMapStringSlice("repo_groups"); strings.Join(groups["all"], ",") != "new,broken" {
+		t.Errorf("expecting the repo groups to be rolled back, got %v", groups)
+	}
+	if value, err := store.Get("repos.broken.token"); err != nil || value != "secret" {
+		t.Errorf("expecting the token to stay in the secret store, got %q, %v", value, err)
+	}
+	if _, err := store.Get("repos.fixed.token"); err == nil {
+		t.Error("expecting no token under the new name in the secret store")
+	}
+
+	for _, names := range [][]string{{"missing", "other"}, {"new", "broken"}} {
+		if err := renameRepo(names[0], names[1]); err == nil {
+			t.Errorf("%s to %s: expecting error", names[0], names[1])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configRepoRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove repo from the config file",
+	Long: `Remove a repo from the config file, with its tokens from the secret store
+and from the repo groups. The default repo is unset if it's the one removed.`,
+	Example: `  $ gitlab-cli config repo rm myrepo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "error: no repo name given\n")
+			os.Exit(1)
+		}
+		name := args[0]
+		if !viper.IsSet("repos." + name) {
+			fmt.Fprintf(os.Stderr, "error: repo '%s' is not in the config file\n", name)
+			os.Exit(1)
+		}
+		if !confirm(fmt.Sprintf("Remove repo '%s' from the config file?", name)) {
+			fmt.Fprintf(os.Stderr, "error: aborted, nothing was changed\n")
+			os.Exit(1)
+		}
+		var secrets []string
+		err := editConfig("repos", func(entries map[string]*repoMap) error {
+			secrets = ownSecrets(entries[name], "repos."+name)
+			delete(entries, name)
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		renameInRepoGroups(name, "")
+		if viper.GetString("default_repo") == name {
+			viper.Set("default_repo", "")
+		}
+		if err := SaveViperConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		deleteSecrets(secrets)
+		fmt.Fprintf(messages(), "Removed repo '%s'\n", name)
+	},
+}
+
+// renameInRepoGroups renames the repo in the repo groups of the config
+// file, or removes it if newName is empty.
+func renameInRepoGroups(oldName, newName string) {
+	groups := viper.GetStringMapStringSlice("repo_groups")
+	changed := false
+	for group, names := range groups {
+		var renamed []string
+		for _, name := range names {
+			if name != oldName {
+				renamed = append(renamed, name)
+			} else if newName != "" {
+				renamed = append(renamed, newName)
+			}
+			if name == oldName {
+				changed = true
+			}
+		}
+		groups[group] = renamed
+	}
+	if changed {
+		viper.Set("repo_groups", groups)
+	}
+}
+
+func init() {
+	configRepoCmd.AddCommand(configRepoRmCmd)
+}
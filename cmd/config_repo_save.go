@@ -31,6 +31,7 @@ var configRepoSaveCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
 		}
+		mustRender(r.output(), nil)
 	},
 }
 
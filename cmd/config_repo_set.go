@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configRepoSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a setting of a repo in the config file",
+	Long: `Set a setting of the repo given by -r, or of the default repo, in the
+config file. An empty value removes the setting. The token is saved in the
+secret store, and removed from it with an empty value.
+
+Keys: url, host, project, token, api_version, retries, retry_backoff,
+insecure, ca_file, cert_file, key_file, proxy, no_proxy (comma separated),
+headers.<Name>, oauth.client_id, oauth.client_secret, oauth.redirect_uri
+and oauth.scopes (comma separated).`,
+	Example: `  $ gitlab-cli config repo set -r myrepo retries 5
+  $ gitlab-cli config repo set -r myrepo headers.X-Gateway-Key <KEY>
+  $ gitlab-cli config repo set -r myrepo proxy ""`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := defaultRepo()
+		if name == "" {
+			fmt.Fprintf(os.Stderr, "error: no repo name given\n")
+			os.Exit(1)
+		}
+		if len(args) != 2 {
+			fmt.Fprintf(os.Stderr, "error: the key and the value are required\n")
+			os.Exit(1)
+		}
+		if !viper.IsSet("repos." + name) {
+			fmt.Fprintf(os.Stderr, "error: repo '%s' is not in the config file\n", name)
+			os.Exit(1)
+		}
+		// the token removed from the secret store, once the config is saved
+		var removed []string
+		err := updateConfig("repos", name, func(rep *repoMap) error {
+			if args[0] == "token" && args[1] == "" {
+				removed = ownSecrets(&repoMap{TokenSecret: rep.TokenSecret}, "repos."+name)
+			}
+			return rep.set("repos."+name, args[0], args[1])
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := SaveViperConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		deleteSecrets(removed)
+		fmt.Fprintf(messages(), "Set %s of repo '%s'\n", args[0], name)
+	},
+}
+
+// set sets the setting with the given key to value, or removes it if
+// value is empty. The token is saved in the secret store, under the
+// config key of the repo.
+func (rep *repoMap) set(configKey, key, value string) error {
+	if strings.HasPrefix(key, "headers.") {
+		name := strings.TrimPrefix(key, "headers.")
+		if name == "" {
+			return fmt.Errorf("no header name given, e.g. headers.X-Gateway-Key")
+		}
+		if rep.Headers == nil {
+			rep.Headers = make(map[string]string)
+		}
+		if value == "" {
+			delete(rep.Headers, name)
+		} else {
+			rep.Headers[name] = value
+		}
+		return nil
+	}
+	if strings.HasPrefix(key, "oauth.") {
+		if rep.OAuth == nil {
+			rep.OAuth = &oauthSettings{}
+		}
+		defer func() {
+			if rep.OAuth.ClientID == "" && rep.OAuth.ClientSecret == "" &&
+				rep.OAuth.RedirectURI == "" && len(rep.OAuth.Scopes) == 0 {
+				rep.OAuth = nil
+			}
+		}()
+	}
+	switch key {
+	case "url":
+		if value != "" {
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid url '%s'", value)
+			}
+		}
+		rep.URL = value
+	case "host":
+		if value != "" && !viper.IsSet("hosts."+value) {
+			return fmt.Errorf("host '%s' is not in the config file", value)
+		}
+		rep.Host = value
+	case "project":
+		rep.Project = strings.Trim(value, "/")
+	case "token":
+		rep.Token, rep.TokenSecret = "", ""
+		return (&Repo{Token: value}).saveToken(rep, configKey)
+	case "api_version":
+		if value != "" && value != gitlab.APIv3 && value != gitlab.APIv4 {
+			return fmt.Errorf("invalid api_version '%s', must be %s or %s", value, gitlab.APIv3, gitlab.APIv4)
+		}
+		rep.APIVersion = value
+	case "retries":
+		rep.Retries = nil
+		if value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid retries '%s', must be a number", value)
+			}
+			rep.Retries = &n
+		}
+	case "retry_backoff":
+		rep.RetryBackoff = nil
+		if value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid retry_backoff '%s', must be a duration like 2s", value)
+			}
+			rep.RetryBackoff = &d
+		}
+	case "insecure":
+		b, err := strconv.ParseBool(value)
+		if err != nil && value != "" {
+			return fmt.Errorf("invalid insecure '%s', must be true or false", value)
+		}
+		rep.Insecure = b
+	case "ca_file":
+		rep.CAFile = value
+	case "cert_file":
+		rep.CertFile = value
+	case "key_file":
+		rep.KeyFile = value
+	case "proxy":
+		rep.Proxy = value
+	case "no_proxy":
+		rep.NoProxy = splitList(value)
+	case "oauth.client_id":
+		rep.OAuth.ClientID = value
+	case "oauth.client_secret":
+		rep.OAuth.ClientSecret = value
+	case "oauth.redirect_uri":
+		rep.OAuth.RedirectURI = value
+	case "oauth.scopes":
+		rep.OAuth.Scopes = splitList(value)
+	default:
+		return fmt.Errorf("unknown key '%s'", key)
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, ignoring empty items.
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func init() {
+	configRepoCmd.AddCommand(configRepoSetCmd)
+}
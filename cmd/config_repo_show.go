@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 
 	"os"
 
@@ -13,16 +14,19 @@ var configRepoShowCmd = &cobra.Command{
 	Short:   "Show repo info from the config file",
 	Example: `  $ gitlab config repo show -r myrepo`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if repo == "" {
+		name := defaultRepo()
+		if name == "" {
 			fmt.Fprintf(os.Stderr, "error: no repo name given\n")
 			os.Exit(1)
 		}
-		r, err := LoadFromConfig(repo)
+		r, err := LoadFromConfig(name)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println(r.String())
+		mustRender(r.output(), func(w io.Writer) {
+			fmt.Fprintln(w, r.String())
+		})
 	},
 }
 
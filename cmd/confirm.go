@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// confirm asks the user a yes/no question and returns true if
+// the answer is yes. It doesn't ask if --yes is given.
+func confirm(question string) bool {
+	if assumeYes {
+		return true
+	}
+	fmt.Fprintf(messages(), "%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
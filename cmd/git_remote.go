@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// gitRemote is a remote of the git repository in the current directory,
+// parsed from its url.
+type gitRemote struct {
+	Name string
+	URL  string
+	// Host and Port are those of the url, Port is "" if not given.
+	Host, Port string
+	// SSH is true for remotes accessed over SSH, whose port (if any)
+	// is not the one of the GitLab web server.
+	SSH bool
+	// Path is the path of the project, without a leading slash or .git.
+	Path string
+}
+
+// findRemote returns the remote with the given name of the git repository
+// in the current directory, or if name is "", 'origin', or the only
+// remote if there's no 'origin'.
+func findRemote(name string) (*gitRemote, error) {
+	out, err := exec.Command("git", "remote", "-v").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the git remotes: %v", err)
+	}
+	// lines like 'origin	git@gitlab.com:group/repo.git (fetch)'
+	urls := map[string]string{}
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if _, ok := urls[fields[0]]; !ok {
+			names = append(names, fields[0])
+		}
+		if _, ok := urls[fields[0]]; !ok || strings.Contains(line, "(fetch)") {
+			urls[fields[0]] = fields[1]
+		}
+	}
+	switch {
+	case name != "":
+	case urls["origin"] != "" || len(names) != 1:
+		name = "origin"
+	default:
+		name = names[0]
+	}
+	rawurl, ok := urls[name]
+	if !ok {
+		return nil, fmt.Errorf("no git remote '%s'", name)
+	}
+	remote, err := parseRemoteURL(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("git remote '%s': %v", name, err)
+	}
+	remote.Name = name
+	return remote, nil
+}
+
+// parseRemoteURL parses the url of a git remote, in any of the forms git
+// supports for GitLab: 'https://host[:port]/path', 'ssh://[user@]host[:port]/path'
+// or the scp-like 'user@host:path'.
+func parseRemoteURL(rawurl string) (*gitRemote, error) {
+	remote := &gitRemote{URL: rawurl}
+	if i := strings.Index(rawurl, ":"); i > 0 && !strings.Contains(rawurl, "://") &&
+		!strings.Contains(rawurl[:i], "/") {
+		// scp-like, the host is up to the first colon
+		host := rawurl[:i]
+		if at := strings.LastIndex(host, "@"); at != -1 {
+			host = host[at+1:]
+		}
+		remote.Host, remote.SSH, remote.Path = host, true, rawurl[i+1:]
+	} else {
+		u, err := url.Parse(rawurl)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("unsupported url '%s'", rawurl)
+		}
+		switch u.Scheme {
+		case "ssh", "git+ssh", "ssh+git":
+			remote.SSH = true
+		case "http", "https":
+		default:
+			return nil, fmt.Errorf("unsupported url '%s'", rawurl)
+		}
+		remote.Host, remote.Port = hostPort(u)
+		remote.Path = u.Path
+	}
+	remote.Path = strings.TrimSuffix(strings.Trim(remote.Path, "/"), ".git")
+	if remote.Host == "" || !strings.Contains(remote.Path, "/") {
+		return nil, fmt.Errorf("no GitLab project in url '%s'", rawurl)
+	}
+	return remote, nil
+}
+
+// on returns true if the remote is on the GitLab instance with the
+// given url. The port is only compared for HTTP remotes, since
+// GitLab's SSH server doesn't share the port of the web server.
+func (remote *gitRemote) on(u *url.URL) bool {
+	host, port := hostPort(u)
+	if !strings.EqualFold(host, remote.Host) {
+		return false
+	}
+	return remote.SSH || port == remote.Port
+}
+
+// hostPort returns the host of the url, without the brackets of an IPv6
+// address, and its port, "" if none or if it's the default one of HTTP
+// or HTTPS.
+func hostPort(u *url.URL) (host, port string) {
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		// no port
+		host, port = strings.Trim(u.Host, "[]"), ""
+	}
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	return host, port
+}
+
+// projectPath returns the path of the remote's project on the GitLab
+// instance with the given url, or "" if the project is not on it.
+// Over HTTP, the remote's path includes the path of the instance, if
+// it isn't at the root (e.g. https://host/gitlab/group/repo), while
+// over SSH it doesn't.
+func (remote *gitRemote) projectPath(u *url.URL) string {
+	if !remote.on(u) {
+		return ""
+	}
+	if remote.SSH {
+		return remote.Path
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix == "" {
+		return remote.Path
+	}
+	if !strings.HasPrefix(strings.ToLower(remote.Path), strings.ToLower(prefix)+"/") {
+		return ""
+	}
+	return remote.Path[len(prefix)+1:]
+}
+
+// is returns true if the remote is the project with the given url.
+func (remote *gitRemote) is(u *url.URL) bool {
+	path, want := strings.ToLower(strings.Trim(u.Path, "/")), strings.ToLower(remote.Path)
+	return remote.on(u) && (path == want || (remote.SSH && strings.HasSuffix(path, "/"+want)))
+}
+
+// repoFromRemote returns the saved repo for the git remote with the
+// given name (see findRemote), or if there's none, the url of its
+// project on a saved host, to be used with the host's credentials.
+// remote is the name of the git remote.
+func repoFromRemote(name string) (repoName, rawurl, remote string, err error) {
+	r, err := findRemote(name)
+	if err != nil {
+		return "", "", "", err
+	}
+	for _, name := range sortedNames("repos") {
+		key := "repos." + name
+		rawurl := viper.GetString(key + ".url")
+		if host := viper.GetString(key + ".host"); host != "" && !viper.IsSet(key+".url") {
+			rawurl = strings.TrimSuffix(viper.GetString("hosts."+host+".url"), "/") + "/" + viper.GetString(key+".project")
+		}
+		if u, err := url.Parse(rawurl); err == nil && r.is(u) {
+			return name, "", r.Name, nil
+		}
+	}
+	for _, name := range sortedNames("hosts") {
+		u, err := url.Parse(viper.GetString("hosts." + name + ".url"))
+		if err != nil {
+			continue
+		}
+		if path := r.projectPath(u); path != "" {
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/" + path
+			return "", u.String(), r.Name, nil
+		}
+	}
+	return "", "", "", fmt.Errorf("git remote '%s' (%s) is not a saved repo or on a saved host, see 'config repo save' and 'config host save'",
+		r.Name, r.URL)
+}
+
+// sortedNames returns the names of the repos or hosts (section)
+// in the config file, sorted.
+func sortedNames(section string) []string {
+	var names []string
+	for name := range viper.GetStringMap(section) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		rawurl     string
+		host, port string
+		ssh        bool
+		path       string
+	}{
+		{"git@gitlab.com:group/repo.git", "gitlab.com", "", true, "group/repo"},
+		{"git@gitlab.com:group/sub/repo.git", "gitlab.com", "", true, "group/sub/repo"},
+		{"gitlab.example.com:group/repo", "gitlab.example.com", "", true, "group/repo"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", "gitlab.example.com", "2222", true, "group/sub/repo"},
+		{"ssh://gitlab.example.com/group/repo", "gitlab.example.com", "", true, "group/repo"},
+		{"git+ssh://git@gitlab.example.com/group/repo.git", "gitlab.example.com", "", true, "group/repo"},
+		{"https://gitlab.com/group/sub/repo.git", "gitlab.com", "", false, "group/sub/repo"},
+		{"https://gitlab.com:443/group/repo", "gitlab.com", "", false, "group/repo"},
+		{"https://user@gitlab.example.com/gitlab/group/repo.git/", "gitlab.example.com", "", false, "gitlab/group/repo"},
+		{"http://localhost:8080/group/repo.git", "localhost", "8080", false, "group/repo"},
+		{"http://gitlab.example.com:80/group/repo", "gitlab.example.com", "", false, "group/repo"},
+		{"http://[::1]:8080/group/repo.git", "::1", "8080", false, "group/repo"},
+		{"https://[::1]/group/repo.git", "::1", "", false, "group/repo"},
+	}
+	for _, test := range tests {
+		remote, err := parseRemoteURL(test.rawurl)
+		if err != nil {
+			t.Errorf("%s: %v", test.rawurl, err)
+			continue
+		}
+		if remote.Host != test.host || remote.Port != test.port || remote.SSH != test.ssh || remote.Path != test.path {
+			t.Errorf("%s: expecting %s, %s, %v and %s, got %+v", test.rawurl, test.host, test.port, test.ssh, test.path, remote)
+		}
+	}
+
+	for _, rawurl := range []string{
+		"/home/user/repo.git",
+		"file:///home/user/repo.git",
+		"ftp://gitlab.com/group/repo.git",
+		"https://gitlab.com/repo.git",
+		"git@gitlab.com:repo.git",
+		"https:///group/repo.git",
+	} {
+		if remote, err := parseRemoteURL(rawurl); err == nil {
+			t.Errorf("%s: expecting error, got %+v", rawurl, remote)
+		}
+	}
+}
+
+func TestGitRemote_ProjectPath(t *testing.T) {
+	tests := []struct {
+		remote, instance string
+		path             string
+	}{
+		{"git@gitlab.com:group/sub/repo.git", "https://gitlab.com", "group/sub/repo"},
+		{"git@GitLab.com:group/repo.git", "https://gitlab.com/", "group/repo"},
+		// the SSH port is not the one of the web server, nor is the path
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", "https://gitlab.example.com:8443/gitlab", "group/sub/repo"},
+		{"https://gitlab.com/group/sub/repo.git", "https://gitlab.com", "group/sub/repo"},
+		{"https://gitlab.com:443/group/repo", "https://gitlab.com:443", "group/repo"},
+		{"https://gitlab.example.com/gitlab/group/repo", "https://gitlab.example.com/gitlab/", "group/repo"},
+		{"http://localhost:8080/group/repo.git", "http://localhost:8080", "group/repo"},
+		{"http://[::1]:8080/group/repo.git", "http://[::1]:8080/", "group/repo"},
+		// not on the instance
+		{"git@gitlab.com:group/repo.git", "https://gitlab.example.com", ""},
+		{"https://gitlab.example.com/other/group/repo", "https://gitlab.example.com/gitlab", ""},
+		{"https://gitlab.example.com/gitlabs/repo", "https://gitlab.example.com/gitlab", ""},
+		{"http://localhost:8080/group/repo.git", "http://localhost:8081", ""},
+		{"http://localhost:8080/group/repo.git", "http://localhost", ""},
+	}
+	for _, test := range tests {
+		remote, err := parseRemoteURL(test.remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(test.instance)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path := remote.projectPath(u); path != test.path {
+			t.Errorf("%s on %s: expecting '%s', got '%s'", test.remote, test.instance, test.path, path)
+		}
+	}
+}
+
+func TestGitRemote_Is(t *testing.T) {
+	tests := []struct {
+		remote, project string
+		is              bool
+	}{
+		{"git@gitlab.com:group/sub/repo.git", "https://gitlab.com/group/sub/repo", true},
+		{"git@gitlab.com:group/repo.git", "https://gitlab.com/Group/Repo/", true},
+		{"ssh://git@gitlab.example.com:2222/group/repo.git", "https://gitlab.example.com/gitlab/group/repo", true},
+		{"https://gitlab.example.com/gitlab/group/repo.git", "https://gitlab.example.com/gitlab/group/repo", true},
+		{"https://gitlab.com/group/repo", "https://gitlab.com/group/sub/repo", false},
+		{"https://gitlab.example.com/group/repo", "https://gitlab.example.com/gitlab/group/repo", false},
+		{"git@gitlab.com:group/repo.git", "https://gitlab.example.com/group/repo", false},
+		{"git@gitlab.com:sub/repo.git", "https://gitlab.com/group/xsub/repo", false},
+	}
+	for _, test := range tests {
+		remote, err := parseRemoteURL(test.remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(test.project)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if is := remote.is(u); is != test.is {
+			t.Errorf("%s is %s: expecting %v, got %v", test.remote, test.project, test.is, is)
+		}
+	}
+}
+
+func TestRepoFromRemote(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitlab-cli-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "git@gitlab.example.com:group/sub/repo.git"},
+		{"remote", "add", "saved", "https://gitlab.com/group/repo.git"},
+		{"remote", "add", "other", "git@github.com:group/repo.git"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v, %s", args, err, out)
+		}
+	}
+
+	viper.Set("hosts", map[string]interface{}{
+		"example": map[string]interface{}{"url": "https://gitlab.example.com/gitlab"},
+	})
+	viper.Set("repos", map[string]interface{}{
+		"myrepo": map[string]interface{}{"url": "https://gitlab.com/group/repo"},
+	})
+	defer viper.Set("hosts", map[string]interface{}{})
+	defer viper.Set("repos", map[string]interface{}{})
+
+	tests := []struct {
+		remote           string
+		repoName, rawurl string
+	}{
+		{"", "", "https://gitlab.example.com/gitlab/group/sub/repo"},
+		{"saved", "myrepo", ""},
+	}
+	for _, test := range tests {
+		repoName, rawurl, remote, err := repoFromRemote(test.remote)
+		if err != nil {
+			t.Errorf("%s: %v", test.remote, err)
+			continue
+		}
+		if repoName != test.repoName || rawurl != test.rawurl || (test.remote != "" && remote != test.remote) {
+			t.Errorf("%s: expecting '%s' and '%s', got '%s', '%s' and '%s'", test.remote, test.repoName, test.rawurl,
+				repoName, rawurl, remote)
+		}
+	}
+	if _, _, _, err := repoFromRemote("other"); err == nil || !strings.Contains(err.Error(), "not a saved repo or on a saved host") {
+		t.Errorf("expecting error for a remote not on a saved host, got %v", err)
+	}
+	if _, _, _, err := repoFromRemote("missing"); err == nil {
+		t.Error("expecting error for a missing remote")
+	}
+}
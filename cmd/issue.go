@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+var issueCmd = &cobra.Command{
+	Use:     "issue",
+	Aliases: []string{"i"},
+	Short:   "Issue actions",
+	Long: `Perform actions on the issues of the repository given by -r or -U.
+
+Issues are referred to by their number in the repository, e.g. 12 or #12.`,
+}
+
+func init() {
+	RootCmd.AddCommand(issueCmd)
+}
+
+// loadIssueRepo returns the repo the issue commands work on, or exits.
+func loadIssueRepo() *Repo {
+	r, err := LoadFromConfig(repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err)
+		os.Exit(1)
+	}
+	return r
+}
+
+// issueIID returns the issue number given as the only argument, or exits.
+func issueIID(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "error: no issue number given\n")
+		os.Exit(1)
+	}
+	iid, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil || iid < 1 {
+		fmt.Fprintf(os.Stderr, "error: invalid issue number '%s'\n", args[0])
+		os.Exit(1)
+	}
+	return iid
+}
+
+// issueOutput is the --output of an issue.
+type issueOutput struct {
+	IID         int        `json:"iid"`
+	Title       string     `json:"title"`
+	State       string     `json:"state"`
+	Labels      []string   `json:"labels"`
+	Milestone   string     `json:"milestone,omitempty"`
+	Assignee    string     `json:"assignee,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	Description string     `json:"description,omitempty"`
+	WebURL      string     `json:"web_url,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+func newIssueOutput(i *gogitlab.Issue) *issueOutput {
+	out := &issueOutput{
+		IID:         i.IID,
+		Title:       i.Title,
+		State:       i.State,
+		Labels:      i.Labels,
+		Description: i.Description,
+		WebURL:      i.WebURL,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+	}
+	if out.Labels == nil {
+		out.Labels = []string{}
+	}
+	if i.Milestone != nil {
+		out.Milestone = i.Milestone.Title
+	}
+	if i.Assignee != nil {
+		out.Assignee = i.Assignee.Username
+	}
+	if i.Author != nil {
+		out.Author = i.Author.Username
+	}
+	return out
+}
+
+// printIssue writes the issue for humans, as 'issue view' shows it.
+func printIssue(w io.Writer, i *issueOutput) {
+	fmt.Fprintf(w, "#%d %s (%s)\n", i.IID, i.Title, i.State)
+	for _, f := range [][2]string{
+		{"author", i.Author},
+		{"assignee", i.Assignee},
+		{"labels", strings.Join(i.Labels, ", ")},
+		{"milestone", i.Milestone},
+		{"url", i.WebURL},
+	} {
+		if f[1] != "" {
+			fmt.Fprintf(w, "  %s: %s\n", f[0], f[1])
+		}
+	}
+	if i.CreatedAt != nil {
+		fmt.Fprintf(w, "  created: %s\n", i.CreatedAt.Format(time.RFC3339))
+	}
+	if i.UpdatedAt != nil {
+		fmt.Fprintf(w, "  updated: %s\n", i.UpdatedAt.Format(time.RFC3339))
+	}
+	if i.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimRight(i.Description, "\n"))
+	}
+}
+
+// readBody returns the contents of the file, or of stdin for "-".
+func readBody(file string) (string, error) {
+	var b []byte
+	var err error
+	if file == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(file)
+	}
+	return string(b), err
+}
+
+// scissors separates the text of an issue or merge request being edited
+// from the help below it, that is ignored.
+const scissors = "# ------------------------ >8 ------------------------"
+
+// editText opens the title and description of an issue or merge request
+// (what) in $VISUAL or $EDITOR, with the title on the first line, and
+// returns them as edited.
+func editText(what, title, description string) (string, string, error) {
+	f, err := ioutil.TempFile("", "gitlab-cli-"+strings.Replace(what, " ", "-", -1)+"-")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(f.Name())
+	text := title + "\n\n" + description + "\n\n" + scissors + `
+# Write the title of the ` + what + ` on the first line and its description
+# after an empty line. Everything from the line above is ignored, an
+# empty title aborts.
+`
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := append(strings.Fields(editor), f.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", "", fmt.Errorf("failed to run the editor '%s': %v", editor, err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", "", err
+	}
+	text = strings.Replace(string(b), "\r\n", "\n", -1)
+	if i := strings.Index(text, scissors); i != -1 {
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, "\n"); i != -1 {
+		title, description = text[:i], text[i+1:]
+	} else {
+		title, description = text, ""
+	}
+	if title = strings.TrimSpace(title); title == "" {
+		return "", "", fmt.Errorf("aborted, the title is empty")
+	}
+	return title, strings.TrimSpace(description), nil
+}
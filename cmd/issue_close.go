@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var issueCloseCmd = &cobra.Command{
+	Use:     "close <issue>",
+	Short:   "Close an issue",
+	Example: `  $ gitlab-cli issue close -r myrepo 12`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := issueIID(args)
+		r := loadIssueRepo()
+		issue, err := r.Client.Issues.Close(r.Project.ID, iid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to close issue #%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		mustRender(newIssueOutput(issue), func(w io.Writer) {
+			fmt.Fprintf(w, "Closed issue #%d %s\n", issue.IID, issue.Title)
+		})
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueCloseCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var (
+	issueTitle, issueBody, issueBodyFile string
+	issueLabels                          []string
+	issueMilestone, issueAssignee        string
+	issueEdit                            bool
+)
+
+var issueCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an issue",
+	Long: `Create an issue in a repository.
+
+The description is read from --body, or from --body-file ('-' for stdin).
+Without --title, or with --edit, the title and description are written in
+$VISUAL or $EDITOR.`,
+	Example: `  $ gitlab-cli issue create -r myrepo --title "Crash on start" --label bug
+  $ gitlab-cli issue create -r myrepo --title "Crash on start" --body-file crash.md
+  $ gitlab-cli issue create -r myrepo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		r := loadIssueRepo()
+		opts, err := issueOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.Title == nil || issueEdit {
+			var title, body string
+			if opts.Title != nil {
+				title = *opts.Title
+			}
+			if opts.Description != nil {
+				body = *opts.Description
+			}
+			if title, body, err = editText("issue", title, body); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Title, opts.Description = &title, &body
+		}
+		issue, err := r.Client.Issues.Create(r.Project.ID, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to create the issue: %v\n", err)
+			os.Exit(1)
+		}
+		out := newIssueOutput(issue)
+		mustRender(out, func(w io.Writer) {
+			fmt.Fprintf(w, "Created issue #%d in '%s'\n", out.IID, r.Path())
+			if out.WebURL != "" {
+				fmt.Fprintln(w, out.WebURL)
+			}
+		})
+	},
+}
+
+// issueOptionsFromFlags returns the fields of the issue given by the
+// flags of 'issue create', 'issue edit' and 'mr create'. The fields whose
+// flags are not given are nil.
+func issueOptionsFromFlags(cmd *cobra.Command) (*gitlab.IssueOptions, error) {
+	opts := &gitlab.IssueOptions{}
+	flags := cmd.Flags()
+	if flags.Changed("title") {
+		opts.Title = &issueTitle
+	}
+	if flags.Changed("body") && flags.Changed("body-file") {
+		return nil, fmt.Errorf("--body and --body-file can't be used together")
+	}
+	if flags.Changed("body") {
+		opts.Description = &issueBody
+	}
+	if flags.Changed("body-file") {
+		body, err := readBody(issueBodyFile)
+		if err != nil {
+			return nil, err
+		}
+		opts.Description = &body
+	}
+	if flags.Changed("label") {
+		opts.Labels = &issueLabels
+	}
+	if flags.Changed("milestone") {
+		opts.Milestone = &issueMilestone
+	}
+	if flags.Changed("assignee") {
+		opts.Assignee = &issueAssignee
+	}
+	return opts, nil
+}
+
+// addIssueFlags adds the flags of the fields of an issue, or of
+// a merge request (what), to cmd.
+func addIssueFlags(cmd *cobra.Command, what string) {
+	cmd.Flags().StringVar(&issueTitle, "title", "", "Title of the "+what)
+	cmd.Flags().StringVarP(&issueBody, "body", "b", "", "Description of the "+what)
+	cmd.Flags().StringVarP(&issueBodyFile, "body-file", "F", "", "Read the description from a file ('-' for stdin)")
+	cmd.Flags().StringSliceVarP(&issueLabels, "label", "l", nil, "Labels of the "+what)
+	cmd.Flags().StringVarP(&issueMilestone, "milestone", "m", "", "Title of the milestone of the "+what)
+	cmd.Flags().StringVarP(&issueAssignee, "assignee", "a", "", "Username of the user to assign the "+what+" to")
+	cmd.Flags().BoolVarP(&issueEdit, "edit", "e", false, "Write the title and description in $EDITOR")
+}
+
+func init() {
+	issueCmd.AddCommand(issueCreateCmd)
+	addIssueFlags(issueCreateCmd, "issue")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var issueEditCmd = &cobra.Command{
+	Use:   "edit <issue>",
+	Short: "Edit an issue",
+	Long: `Change the title, description, labels, milestone or assignee of an issue.
+Only the fields given by flags are changed; an empty --milestone or --assignee
+removes it and --label replaces all the labels.
+
+Without flags, or with --edit, the title and description are edited in
+$VISUAL or $EDITOR.`,
+	Example: `  $ gitlab-cli issue edit -r myrepo 12 --label bug,critical --assignee my_user
+  $ gitlab-cli issue edit -r myrepo 12 --milestone ""
+  $ gitlab-cli issue edit -r myrepo 12`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := issueIID(args)
+		r := loadIssueRepo()
+		opts, err := issueOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if issueEdit || *opts == (gitlab.IssueOptions{}) {
+			issue, err := r.Client.Issues.Get(r.Project.ID, iid)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			title, body := issue.Title, issue.Description
+			if opts.Title != nil {
+				title = *opts.Title
+			}
+			if opts.Description != nil {
+				body = *opts.Description
+			}
+			if title, body, err = editText("issue", title, body); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Title, opts.Description = &title, &body
+		}
+		issue, err := r.Client.Issues.Update(r.Project.ID, iid, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to edit issue #%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		out := newIssueOutput(issue)
+		mustRender(out, func(w io.Writer) {
+			printIssue(w, out)
+		})
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueEditCmd)
+	addIssueFlags(issueEditCmd, "issue")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var issueListOpts gitlab.ListIssuesOptions
+
+var issueListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the issues of a repository",
+	Long: `List the issues of a repository, newest first. Only the open issues are
+listed, unless --state is given.`,
+	Example: `  $ gitlab-cli issue list -r myrepo
+  $ gitlab-cli issue list -r myrepo --label bug --label critical --assignee my_user
+  $ gitlab-cli issue list -r myrepo --state closed --milestone v1.0 --search crash`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := issueListOpts
+		switch opts.State {
+		case gitlab.IssueOpened, gitlab.IssueClosed:
+		case "all":
+			opts.State = ""
+		default:
+			fmt.Fprintf(os.Stderr, "error: invalid state '%s', must be opened, closed or all\n", opts.State)
+			os.Exit(1)
+		}
+		r := loadIssueRepo()
+		issues, err := r.Client.Issues.List(r.Project.ID, &opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		out := make([]*issueOutput, len(issues))
+		for i, issue := range issues {
+			out[i] = newIssueOutput(issue)
+		}
+		mustRender(out, func(w io.Writer) {
+			if len(out) == 0 {
+				fmt.Fprintln(w, "No issues found")
+				return
+			}
+			tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "ISSUE\tTITLE\tSTATE\tLABELS\tMILESTONE\tASSIGNEE")
+			for _, i := range out {
+				fmt.Fprintf(tw, "#%d\t%s\t%s\t%s\t%s\t%s\n", i.IID, i.Title, i.State,
+					orDash(strings.Join(i.Labels, ",")), orDash(i.Milestone), orDash(i.Assignee))
+			}
+			tw.Flush()
+		})
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueListCmd)
+
+	issueListCmd.Flags().StringVar(&issueListOpts.State, "state", gitlab.IssueOpened, "State of the issues: opened, closed or all")
+	issueListCmd.Flags().StringSliceVarP(&issueListOpts.Labels, "label", "l", nil, "Only issues with all these labels")
+	issueListCmd.Flags().StringVarP(&issueListOpts.Milestone, "milestone", "m", "", "Only issues in the milestone with this title")
+	issueListCmd.Flags().StringVarP(&issueListOpts.Assignee, "assignee", "a", "", "Only issues assigned to the user with this username")
+	issueListCmd.Flags().StringVarP(&issueListOpts.Search, "search", "s", "", "Only issues with this text in the title or description")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var issueReopenCmd = &cobra.Command{
+	Use:     "reopen <issue>",
+	Short:   "Reopen a closed issue",
+	Example: `  $ gitlab-cli issue reopen -r myrepo 12`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := issueIID(args)
+		r := loadIssueRepo()
+		issue, err := r.Client.Issues.Reopen(r.Project.ID, iid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to reopen issue #%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		mustRender(newIssueOutput(issue), func(w io.Writer) {
+			fmt.Fprintf(w, "Reopened issue #%d %s\n", issue.IID, issue.Title)
+		})
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueReopenCmd)
+}
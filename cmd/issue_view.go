@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var issueViewWeb bool
+
+var issueViewCmd = &cobra.Command{
+	Use:   "view <issue>",
+	Short: "Show an issue",
+	Example: `  $ gitlab-cli issue view -r myrepo 12
+  $ gitlab-cli issue view -r myrepo 12 --web`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := issueIID(args)
+		r := loadIssueRepo()
+		issue, err := r.Client.Issues.Get(r.Project.ID, iid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if issueViewWeb && issue.WebURL != "" {
+			openBrowser(issue.WebURL)
+			return
+		}
+		out := newIssueOutput(issue)
+		mustRender(out, func(w io.Writer) {
+			printIssue(w, out)
+		})
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueViewCmd)
+
+	issueViewCmd.Flags().BoolVarP(&issueViewWeb, "web", "w", false, "Open the issue in the browser")
+}
@@ -1,14 +1,120 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"io"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+var labelGroup string
 
 var labelCmd = &cobra.Command{
 	Use:     "label",
 	Aliases: []string{"l"},
 	Short:   "Label actions",
-	Long:    `Perform actions on labels.`,
+	Long: `Perform actions on labels.
+
+The label commands work on the labels of the repository given by -r or -U.
+With --group they work on the labels of that GitLab group instead, which are
+inherited by all its projects. The group must be on the same GitLab instance
+as the repository; with -U only the host of the url is used. Group labels
+need GitLab 9.0 or newer (API v4).`,
 }
 
 func init() {
 	RootCmd.AddCommand(labelCmd)
+
+	labelCmd.PersistentFlags().StringVar(&labelGroup, "group", "", "Work on the labels of this GitLab group (e.g. 'group/subgroup')")
+}
+
+// loadLabelsRepo returns the repo the label commands work on:
+// the -r/-U repo or, with --group, the group.
+func loadLabelsRepo() (*Repo, error) {
+	if labelGroup != "" {
+		return LoadGroupFromConfig(repo, labelGroup)
+	}
+	return LoadFromConfig(repo)
+}
+
+// applyLabelChanges prints and makes the given label changes to the repo.
+// With --dry-run it only prints them. Deleting labels asks for confirmation
+// first, unless --yes is given.
+func applyLabelChanges(r *Repo, changes []*gitlab.LabelChange) error {
+	counts := countLabelChanges(changes)
+	printLabelChanges(r, changes)
+	if len(changes) == counts["skip"] {
+		return render(newLabelChangesOutput(r, changes), nil)
+	}
+	if dryRun {
+		fmt.Fprintln(messages(), "Dry run, nothing was changed.")
+		return render(newLabelChangesOutput(r, changes), nil)
+	}
+	if counts["delete"] > 0 && !confirm(fmt.Sprintf("Delete %d label(s) from '%s'?",
+		counts["delete"], r.Path())) {
+		return fmt.Errorf("aborted, nothing was changed")
+	}
+	if err := r.Client.Labels.ApplyChanges(r.LabelsID(), changes); err != nil {
+		return err
+	}
+	return render(newLabelChangesOutput(r, changes), func(w io.Writer) {
+		fmt.Fprintf(w, "%d created, %d updated, %d deleted, %d skipped\n",
+			counts["create"], counts["update"], counts["delete"], counts["skip"])
+	})
+}
+
+func printLabelChanges(r *Repo, changes []*gitlab.LabelChange) {
+	w := messages()
+	if len(changes) == countLabelChanges(changes)["skip"] {
+		fmt.Fprintf(w, "'%s': labels are up to date\n", r.Path())
+		return
+	}
+	fmt.Fprintf(w, "Label changes in '%s':\n", r.Path())
+	for _, c := range changes {
+		fmt.Fprintln(w, "  "+c.String())
+	}
+}
+
+// labelChangesOutput is the --output of the commands that change labels.
+type labelChangesOutput struct {
+	Repo    string               `json:"repo"`
+	DryRun  bool                 `json:"dry_run"`
+	Changes []*labelChangeOutput `json:"changes"`
+	Counts  map[string]int       `json:"counts"`
+}
+
+type labelChangeOutput struct {
+	Action string          `json:"action"`
+	Name   string          `json:"name"`
+	Old    *gogitlab.Label `json:"old,omitempty"`
+	New    *gogitlab.Label `json:"new,omitempty"`
+}
+
+func newLabelChangesOutput(r *Repo, changes []*gitlab.LabelChange) *labelChangesOutput {
+	out := &labelChangesOutput{
+		Repo:    r.Path(),
+		DryRun:  dryRun,
+		Changes: []*labelChangeOutput{},
+		Counts:  countLabelChanges(changes),
+	}
+	for _, c := range changes {
+		out.Changes = append(out.Changes, &labelChangeOutput{
+			Action: c.Action(),
+			Name:   c.Name(),
+			Old:    c.Old,
+			New:    c.New,
+		})
+	}
+	return out
+}
+
+// countLabelChanges returns the number of changes by action.
+func countLabelChanges(changes []*gitlab.LabelChange) map[string]int {
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Action()]++
+	}
+	return counts
 }
@@ -2,12 +2,22 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
 
+	"github.com/clns/gitlab-cli/gitlab"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	gogitlab "github.com/xanzy/go-gitlab"
 )
 
 var fromRepo string
+var onConflict string
+var toRepos []string
+var copyWorkers int
 
 var labelCopyCmd = &cobra.Command{
 	Use:     "copy",
@@ -16,44 +26,101 @@ var labelCopyCmd = &cobra.Command{
 	Long: `Copy labels into a repository.
 
 If --from is omitted, it will copy global labels. If --from is specified,
-it will copy all labels from that repository.
+it will copy all labels from that repository. The global labels can't be
+previewed with --dry-run, since reading them creates and deletes a
+temporary project.
+
+With --group, the labels are copied into that group instead of a repository.
 
 The from repo can be a repo name as in the config file or a relative path
 as group/repo (e.g. 'myuser/myrepo'). In the later case it will use the url
-of the target repo, so the repositories need to be on the same GitLab instance.`,
+of the target repo, so the repositories need to be on the same GitLab instance.
+
+To copy into many repositories at once, use --to (in addition to or instead
+of -r/-U). It can be given multiple times or as a comma-separated list of:
+
+  - repo names from the config file
+  - repo groups from the config file, as in 'repo_groups.<name>: [repo1, repo2]'
+  - project paths (e.g. 'group/repo') on the same GitLab instance as the
+    source repo (or the -r/-U repo when copying global labels)
+  - GitLab group or user paths (e.g. 'group/subgroup'), meaning all their projects
+
+The copies run in parallel (see --workers) and a table with the result for
+each target is printed at the end.
+
+Labels that already exist in the target repo are handled by --on-conflict:
+
+  skip       keep the existing label (default)
+  overwrite  update the color and description of the existing label
+  rename     copy the label with a new name, e.g. 'bug (2)'
+  fail       don't copy anything`,
 	Example: `  $ gitlab label copy -U https://gitlab.com/user/myrepo -t <TOKEN>
+  $ gitlab label copy --from sourceRepo -r targetRepo --dry-run
   $ gitlab label copy --from sourceRepo -r targetRepo
-  $ gitlab label copy --from group/repo -r targetRepo`,
+  $ gitlab label copy --from group/repo -r targetRepo
+  $ gitlab label copy --from sourceRepo -r targetRepo --on-conflict overwrite
+  $ gitlab label copy --from sourceRepo --to repoA,repoB --to mygroup/subgroup
+  $ gitlab label copy --from sourceRepo --group mygroup`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
 			from, to *Repo
 			err      error
 		)
-		if to, err = LoadFromConfig(repo); err != nil {
-			fmt.Fprintf(os.Stderr, "error: invalid target repository: %v\n", err.Error())
-			os.Exit(1)
+		if len(toRepos) == 0 || repo != "" || labelGroup != "" || viper.GetString("_url") != "" {
+			if to, err = loadLabelsRepo(); err != nil {
+				fmt.Fprintf(os.Stderr, "error: invalid target repository: %v\n", err.Error())
+				os.Exit(1)
+			}
 		}
 		if fromRepo != "" {
-			if from, err = LoadFromConfig(fromRepo); err != nil {
+			if from, err = LoadFromConfigRelativeTo(fromRepo, to); err != nil {
 				fmt.Fprintf(os.Stderr, "error: invalid source repository: %v\n", err.Error())
 				os.Exit(1)
 			}
 		}
+		if from == nil && dryRun {
+			fmt.Fprintln(os.Stderr, "error: the global labels can't be previewed with --dry-run, "+
+				"reading them creates and deletes a temporary project")
+			os.Exit(1)
+		}
+		labels := &sourceLabels{global: make(map[string][]*gogitlab.Label)}
+		if from != nil {
+			labels.fromRepo = true
+			if labels.labels, _, err = from.Client.Labels.ListLabels(from.LabelsID()); err != nil {
+				fmt.Fprintf(os.Stderr, "error: '%s': %v\n", from.Path(), err)
+				os.Exit(1)
+			}
+		}
 
-		if from == nil {
-			// we need to copy the global labels
-			if err := to.Client.Labels.CopyGlobalLabelsTo(to.Project.ID); err != nil {
+		if len(toRepos) == 0 {
+			changes, err := labels.planCopy(to)
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "error: '%s': %v\n",
-					to.Project.PathWithNamespace, err)
+					to.Path(), err)
 				os.Exit(1)
 			}
-		} else {
-			// we need to copy labels from one project to another
-			if err := to.Client.Labels.CopyLabels(from.Project.ID, to.Project.ID); err != nil {
-				fmt.Fprintf(os.Stderr, "error: '%s' to '%s': %v\n",
-					from.Project.PathWithNamespace, to.Project.PathWithNamespace, err)
+			if err := applyLabelChanges(to, changes); err != nil {
+				fmt.Fprintf(os.Stderr, "error: '%s': %v\n",
+					to.Path(), err)
 				os.Exit(1)
 			}
+			return
+		}
+
+		base := from
+		if to != nil {
+			base = to
+		}
+		targets, err := loadCopyTargets(toRepos, base)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid target repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if to != nil {
+			targets = append([]*Repo{to}, targets...)
+		}
+		if !printCopyResults(copyToAll(targets, labels)) {
+			os.Exit(1)
 		}
 	},
 }
@@ -62,4 +129,182 @@ func init() {
 	labelCmd.AddCommand(labelCopyCmd)
 
 	labelCopyCmd.Flags().StringVar(&fromRepo, "from", "", "Source repository (optional)")
+	labelCopyCmd.Flags().StringVar(&onConflict, "on-conflict", gitlab.ConflictSkip, "What to do with existing labels: skip, overwrite, rename or fail")
+	labelCopyCmd.Flags().StringSliceVar(&toRepos, "to", nil, "Target repositories, repo groups or GitLab groups (optional)")
+	labelCopyCmd.Flags().IntVar(&copyWorkers, "workers", 4, "Number of targets to copy into at the same time")
+}
+
+// sourceLabels holds the labels to copy: either the ones from the
+// source repo or, if there's none, the global labels of every GitLab
+// instance, fetched once per instance.
+type sourceLabels struct {
+	fromRepo bool
+	labels   []*gogitlab.Label
+
+	mu     sync.Mutex
+	global map[string][]*gogitlab.Label
+}
+
+// planCopy returns the changes that copy the labels into the repo.
+func (s *sourceLabels) planCopy(r *Repo) ([]*gitlab.LabelChange, error) {
+	labels := s.labels
+	if !s.fromRepo {
+		var err error
+		if labels, err = s.globalLabels(r); err != nil {
+			return nil, err
+		}
+	}
+	return r.Client.Labels.PlanCopy(labels, r.LabelsID(), onConflict)
+}
+
+func (s *sourceLabels) globalLabels(r *Repo) ([]*gogitlab.Label, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	host := r.URL.Host
+	if labels, ok := s.global[host]; ok {
+		return labels, nil
+	}
+	labels, err := r.Client.Labels.GlobalLabels()
+	if err != nil {
+		return nil, err
+	}
+	s.global[host] = labels
+	return labels, nil
+}
+
+// loadCopyTargets returns the repos given by the --to entries, which
+// are relative to base, without duplicates.
+func loadCopyTargets(entries []string, base *Repo) ([]*Repo, error) {
+	var targets []*Repo
+	seen := make(map[string]bool)
+	add := func(r *Repo) {
+		if key := r.URL.Host + "/" + r.Path(); !seen[key] {
+			seen[key] = true
+			targets = append(targets, r)
+		}
+	}
+	for _, entry := range entries {
+		if group := "repo_groups." + entry; viper.IsSet(group) {
+			for _, name := range viper.GetStringSlice(group) {
+				r, err := LoadFromConfigRelativeTo(name, base)
+				if err != nil {
+					return nil, fmt.Errorf("'%s' in repo group '%s': %v", name, entry, err)
+				}
+				add(r)
+			}
+			continue
+		}
+		r, err := LoadFromConfigRelativeTo(entry, base)
+		if err == nil {
+			add(r)
+			continue
+		}
+		if base == nil || viper.IsSet("repos."+entry) {
+			return nil, fmt.Errorf("'%s': %v", entry, err)
+		}
+		// not a project, maybe a group
+		projects, gerr := base.Client.Projects.ByGroup(entry)
+		if gerr != nil {
+			return nil, fmt.Errorf("'%s' is neither a project (%v) nor a group: %v", entry, err, gerr)
+		}
+		for _, p := range projects {
+			u := *base.URL
+			u.Path = "/" + p.PathWithNamespace
+			add(&Repo{
+				Client:         base.Client,
+				Project:        p,
+				Name:           p.PathWithNamespace,
+				Url_:           u.String(),
+				URL:            &u,
+				Token:          base.Token,
+				ClientSettings: base.ClientSettings,
+			})
+		}
+	}
+	return targets, nil
+}
+
+// copyResult is the result of copying the labels into one target.
+type copyResult struct {
+	target  *Repo
+	changes []*gitlab.LabelChange
+	counts  map[string]int
+	err     error
+}
+
+// copyToAll copies the labels into all the targets, using at most
+// --workers goroutines. The results are in the same order as the targets.
+func copyToAll(targets []*Repo, labels *sourceLabels) []*copyResult {
+	results := make([]*copyResult, len(targets))
+	gitlab.ForEach(len(targets), copyWorkers, func(i int) {
+		results[i] = copyTo(targets[i], labels)
+	})
+	return results
+}
+
+func copyTo(target *Repo, labels *sourceLabels) *copyResult {
+	res := &copyResult{target: target}
+	changes, err := labels.planCopy(target)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	res.changes, res.counts = changes, countLabelChanges(changes)
+	if !dryRun {
+		res.err = target.Client.Labels.ApplyChanges(target.LabelsID(), changes)
+	}
+	return res
+}
+
+// printCopyResults prints a table with the result for each target and
+// returns false if any of the copies failed. On a dry run, the changes
+// planned for each target are printed first, as for a single target.
+func printCopyResults(results []*copyResult) bool {
+	ok := true
+	out := make([]*copyResultOutput, len(results))
+	for i, res := range results {
+		out[i] = &copyResultOutput{
+			Target:  res.target.Path(),
+			DryRun:  dryRun,
+			Created: res.counts["create"],
+			Updated: res.counts["update"],
+			Skipped: res.counts["skip"],
+		}
+		if dryRun && res.err == nil {
+			printLabelChanges(res.target, res.changes)
+			out[i].Changes = newLabelChangesOutput(res.target, res.changes).Changes
+		}
+		if res.err != nil {
+			out[i].Error = res.err.Error()
+			ok = false
+		}
+	}
+	mustRender(out, func(o io.Writer) {
+		w := tabwriter.NewWriter(o, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "TARGET\tCREATED\tUPDATED\tSKIPPED\tRESULT")
+		for _, res := range out {
+			status := "ok"
+			if res.Error != "" {
+				status = "error: " + strings.Replace(res.Error, "\n", "; ", -1)
+			} else if dryRun {
+				status = "dry run"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", res.Target,
+				res.Created, res.Updated, res.Skipped, status)
+		}
+		w.Flush()
+	})
+	return ok
+}
+
+// copyResultOutput is the --output of copying into many targets.
+type copyResultOutput struct {
+	Target  string `json:"target"`
+	DryRun  bool   `json:"dry_run"`
+	Created int    `json:"created"`
+	Updated int    `json:"updated"`
+	Skipped int    `json:"skipped"`
+	// Changes are the planned changes, on a dry run.
+	Changes []*labelChangeOutput `json:"changes,omitempty"`
+	Error   string               `json:"error,omitempty"`
 }
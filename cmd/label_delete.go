@@ -16,20 +16,30 @@ var labelDeleteCmd = &cobra.Command{
 	Long: `Delete labels from a repository.
 
 The --match flag can be specified as a Go regexp pattern to delete only
-labels that match. If ommitted, all repository labels will be deleted.`,
+labels that match. If ommitted, all repository labels will be deleted.
+
+The labels to delete are printed and you are asked to confirm, unless --yes
+is given. Use --dry-run to only print them.`,
 	Example: `  $ gitlab label delete -r myrepo
-  $ gitlab label delete -r myrepo --match=".*:.*"`,
+  $ gitlab label delete -r myrepo --match=".*:.*"
+  $ gitlab label delete -r myrepo --match=".*:.*" --yes
+  $ gitlab label delete -r myrepo --group mygroup --match="^wontfix$"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
 			to  *Repo
 			err error
 		)
-		if to, err = LoadFromConfig(repo); err != nil {
+		if to, err = loadLabelsRepo(); err != nil {
 			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
 			os.Exit(1)
 		}
 
-		if err := to.Client.Labels.DeleteWithRegex(to.Project.ID, regexpLabel); err != nil {
+		changes, err := to.Client.Labels.PlanDeleteWithRegex(to.LabelsID(), regexpLabel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if err := applyLabelChanges(to, changes); err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
 			os.Exit(1)
 		}
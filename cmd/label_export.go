@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+var exportFormat string
+var exportFile string
+
+var labelExportCmd = &cobra.Command{
+	Use:     "export",
+	Aliases: []string{"e"},
+	Short:   "Export the labels of a repository",
+	Long: `Export the labels of a repository.
+
+Writes the name, color, description and the open/closed issues and open merge
+requests counts of every label, sorted by name. The yaml and json formats are
+manifests that can be used with 'label sync'.`,
+	Example: `  $ gitlab label export -r myrepo
+  $ gitlab label export -r myrepo --format json -f labels.json
+  $ gitlab label export -r myrepo --format csv > labels.csv
+  $ gitlab label export -r myrepo --group mygroup`,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := loadLabelsRepo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+		labels, _, err := from.Client.Labels.ListLabels(from.LabelsID(), gitlab.WithCounts())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+
+		if exportFile == "" {
+			err = writeLabelManifest(os.Stdout, exportFormat, labels)
+		} else {
+			err = writeLabelManifestFile(exportFile, exportFormat, labels)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	labelCmd.AddCommand(labelExportCmd)
+
+	labelExportCmd.Flags().StringVar(&exportFormat, "format", "yaml", "Output format: yaml, json or csv")
+	labelExportCmd.Flags().StringVarP(&exportFile, "file", "f", "", "Output file (default is stdout)")
+}
+
+// writeLabelManifestFile writes the labels to a temporary file next to
+// path, that replaces path once complete, so that a failed export
+// doesn't leave an empty or partial file behind.
+func writeLabelManifestFile(path, format string, labels []*gogitlab.Label) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	// TempFile creates it only readable by the user
+	err = f.Chmod(0644)
+	if err == nil {
+		err = writeLabelManifest(f, format, labels)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+var labelListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the labels of a repository",
+	Long: `List the labels of a repository, sorted by name.
+
+Use 'label export' to get the labels in a machine readable format.`,
+	Example: `  $ gitlab label list -r myrepo
+  $ gitlab label list -r myrepo --group mygroup`,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := loadLabelsRepo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+		labels, _, err := from.Client.Labels.ListLabels(from.LabelsID())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if labels == nil {
+			labels = []*gogitlab.Label{}
+		}
+		sort.Sort(labelsByName(labels))
+
+		mustRender(labels, func(out io.Writer) {
+			w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tCOLOR\tDESCRIPTION")
+			for _, l := range labels {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", l.Name, l.Color, l.Description)
+			}
+			w.Flush()
+		})
+	},
+}
+
+func init() {
+	labelCmd.AddCommand(labelListCmd)
+}
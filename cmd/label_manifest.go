@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	gogitlab "github.com/xanzy/go-gitlab"
+	"gopkg.in/yaml.v2"
+)
+
+// labelManifest is the file format used to describe a set of labels.
+type labelManifest struct {
+	Labels []*gitlab.LabelSpec `yaml:"labels" json:"labels"`
+}
+
+// exportedLabel is a label as written by label export. The usage
+// counts are informative only, they are ignored when reading it back.
+type exportedLabel struct {
+	gitlab.LabelSpec       `yaml:",inline"`
+	OpenIssuesCount        int `yaml:"open_issues_count,omitempty" json:"open_issues_count,omitempty"`
+	ClosedIssuesCount      int `yaml:"closed_issues_count,omitempty" json:"closed_issues_count,omitempty"`
+	OpenMergeRequestsCount int `yaml:"open_merge_requests_count,omitempty" json:"open_merge_requests_count,omitempty"`
+}
+
+// readLabelManifest reads the labels from a YAML or JSON manifest file,
+// based on its extension. A file named "-" is read from stdin, as YAML
+// (which also accepts JSON).
+func readLabelManifest(file string) ([]*gitlab.LabelSpec, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if file == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m labelManifest
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		err = json.Unmarshal(b, &m)
+	} else {
+		err = yaml.Unmarshal(b, &m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest '%s': %v", file, err)
+	}
+	return m.Labels, nil
+}
+
+// writeLabelManifest writes the labels, sorted by name, in the given
+// format: "yaml" or "json", that can be read back by readLabelManifest,
+// or "csv".
+func writeLabelManifest(w io.Writer, format string, labels []*gogitlab.Label) error {
+	exported := make([]*exportedLabel, len(labels))
+	for i, l := range labels {
+		exported[i] = &exportedLabel{
+			LabelSpec: gitlab.LabelSpec{
+				Name:        l.Name,
+				Color:       l.Color,
+				Description: l.Description,
+			},
+			OpenIssuesCount:        l.OpenIssuesCount,
+			ClosedIssuesCount:      l.ClosedIssuesCount,
+			OpenMergeRequestsCount: l.OpenMergeRequestsCount,
+		}
+	}
+	sort.Sort(exportedByName(exported))
+
+	switch format {
+	case "yaml":
+		b, err := yaml.Marshal(map[string]interface{}{"labels": exported})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case "json":
+		b, err := json.MarshalIndent(map[string]interface{}{"labels": exported}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"name", "color", "description",
+			"open_issues_count", "closed_issues_count", "open_merge_requests_count"})
+		for _, l := range exported {
+			cw.Write([]string{l.Name, l.Color, l.Description,
+				strconv.Itoa(l.OpenIssuesCount),
+				strconv.Itoa(l.ClosedIssuesCount),
+				strconv.Itoa(l.OpenMergeRequestsCount)})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown format '%s', must be one of: yaml, json, csv", format)
+}
+
+type exportedByName []*exportedLabel
+
+func (l exportedByName) Len() int           { return len(l) }
+func (l exportedByName) Less(i, j int) bool { return l[i].Name < l[j].Name }
+func (l exportedByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+type labelsByName []*gogitlab.Label
+
+func (l labelsByName) Len() int           { return len(l) }
+func (l labelsByName) Less(i, j int) bool { return l[i].Name < l[j].Name }
+func (l labelsByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
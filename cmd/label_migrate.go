@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateFrom, migrateTo string
+	migrateDelete          bool
+)
+
+var labelMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move issues and merge requests from some labels to another",
+	Long: `Move the issues and merge requests of a repository from the labels that
+match --from-match to the --to label, e.g. to merge 'bug' and 'defect'.
+
+The issues and merge requests, open and closed, that have a matching label
+get the --to label, which is created if missing, and lose the matching ones.
+With --delete, the labels they were moved from are deleted afterwards; you
+are asked to confirm, unless --yes is given.
+
+Use --dry-run to only print the changes.`,
+	Example: `  $ gitlab label migrate -r myrepo --from-match "^defect$" --to bug --dry-run
+  $ gitlab label migrate -r myrepo --from-match "(?i)^(bug|defect)$" --to bug --delete`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if migrateFrom == "" || migrateTo == "" {
+			fmt.Fprintf(os.Stderr, "error: --from-match and --to are required\n")
+			os.Exit(1)
+		}
+		if labelGroup != "" {
+			fmt.Fprintf(os.Stderr, "error: --group is not supported, migrate the labels of each repository\n")
+			os.Exit(1)
+		}
+		r, err := LoadFromConfig(repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+
+		m, err := r.Client.Labels.PlanMigrate(r.Project.ID, migrateFrom, migrateTo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		var deletes []*gitlab.LabelChange
+		if migrateDelete {
+			for _, l := range m.From {
+				deletes = append(deletes, &gitlab.LabelChange{Old: l})
+			}
+		}
+		result := newMigrateOutput(r, m, deletes)
+		if len(m.From) == 0 && len(m.Moves) == 0 {
+			fmt.Fprintf(messages(), "'%s': no labels match '%s'\n", r.Path(), migrateFrom)
+			mustRender(result, nil)
+			return
+		}
+		printMigration(r, m, deletes)
+		if dryRun {
+			fmt.Fprintln(messages(), "Dry run, nothing was changed.")
+			mustRender(result, nil)
+			return
+		}
+		if len(deletes) > 0 && !confirm(fmt.Sprintf("Delete %d label(s) from '%s' once migrated?", len(deletes), r.Path())) {
+			fmt.Fprintf(os.Stderr, "error: aborted, nothing was changed\n")
+			os.Exit(1)
+		}
+
+		if err := r.Client.Labels.Migrate(r.Project.ID, m); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if len(deletes) > 0 {
+			if err := r.Client.Labels.ApplyChanges(r.Project.ID, deletes); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+				os.Exit(1)
+			}
+		}
+		mustRender(result, func(w io.Writer) {
+			fmt.Fprintf(w, "%d issue(s) and merge request(s) relabeled, %d label(s) deleted\n",
+				len(m.Moves), len(deletes))
+		})
+	},
+}
+
+func printMigration(r *Repo, m *gitlab.LabelMigration, deletes []*gitlab.LabelChange) {
+	w := messages()
+	var from []string
+	for _, l := range m.From {
+		from = append(from, "'"+l.Name+"'")
+	}
+	if len(from) == 0 {
+		// only on issues or merge requests, not a label of the repository
+		from = append(from, "the labels matching '"+migrateFrom+"'")
+	}
+	fmt.Fprintf(w, "Label migration in '%s' from %s to '%s':\n", r.Path(), strings.Join(from, ", "), m.To.Name)
+	if m.Create && len(m.Moves) > 0 {
+		fmt.Fprintf(w, "  create '%s' (%s)\n", m.To.Name, m.To.Color)
+	}
+	if len(m.Moves) == 0 {
+		fmt.Fprintln(w, "  no issues or merge requests to relabel")
+	}
+	for _, move := range m.Moves {
+		fmt.Fprintf(w, "  relabel %s %s: %s → %s\n", move.Item, move.Item.Title,
+			strings.Join(move.Old, ", "), strings.Join(move.New, ", "))
+	}
+	for _, c := range deletes {
+		fmt.Fprintln(w, "  "+c.String())
+	}
+}
+
+// migrateOutput is the --output of label migrate.
+type migrateOutput struct {
+	Repo    string             `json:"repo"`
+	DryRun  bool               `json:"dry_run"`
+	From    []string           `json:"from"`
+	To      string             `json:"to"`
+	Created bool               `json:"created"`
+	Moves   []*labelMoveOutput `json:"moves"`
+	Deleted []string           `json:"deleted"`
+}
+
+type labelMoveOutput struct {
+	Kind  string   `json:"kind"`
+	IID   int      `json:"iid"`
+	Title string   `json:"title"`
+	Old   []string `json:"old"`
+	New   []string `json:"new"`
+}
+
+func newMigrateOutput(r *Repo, m *gitlab.LabelMigration, deletes []*gitlab.LabelChange) *migrateOutput {
+	out := &migrateOutput{
+		Repo:    r.Path(),
+		DryRun:  dryRun,
+		From:    []string{},
+		To:      m.To.Name,
+		Created: m.Create && len(m.Moves) > 0,
+		Moves:   []*labelMoveOutput{},
+		Deleted: []string{},
+	}
+	for _, l := range m.From {
+		out.From = append(out.From, l.Name)
+	}
+	for _, move := range m.Moves {
+		out.Moves = append(out.Moves, &labelMoveOutput{
+			Kind:  move.Item.Kind,
+			IID:   move.Item.IID,
+			Title: move.Item.Title,
+			Old:   move.Old,
+			New:   move.New,
+		})
+	}
+	for _, c := range deletes {
+		out.Deleted = append(out.Deleted, c.Name())
+	}
+	return out
+}
+
+func init() {
+	labelCmd.AddCommand(labelMigrateCmd)
+
+	labelMigrateCmd.Flags().StringVar(&migrateFrom, "from-match", "", "Labels to migrate from, as a Go regex (https://golang.org/pkg/regexp/syntax)")
+	labelMigrateCmd.Flags().StringVar(&migrateTo, "to", "", "Label to migrate to")
+	labelMigrateCmd.Flags().BoolVar(&migrateDelete, "delete", false, "Delete the labels migrated from")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+var (
+	promoteMatch    string
+	promoteRecreate bool
+)
+
+var labelPromoteCmd = &cobra.Command{
+	Use:     "promote",
+	Aliases: []string{"p"},
+	Short:   "Promote labels of a repository to group labels",
+	Long: `Promote labels of a repository to group labels.
+
+The labels of the repository that match --match (all if omitted) become labels
+of the group given by --group, which defaults to the group of the repository,
+and are removed from the repository. The group must contain the repository.
+
+GitLab 12.3 and newer promote the labels to the group of the repository and
+keep them on issues and merge requests. On older versions, or to promote to
+another ancestor group, --recreate is needed: the repository labels are
+deleted after the group labels are created, which removes them from the issues
+and merge requests that have them.
+
+The labels to promote are printed and you are asked to confirm, unless --yes
+is given. Use --dry-run to only print them.`,
+	Example: `  $ gitlab label promote -r myrepo --dry-run
+  $ gitlab label promote -r myrepo --group mygroup --match "^type/"
+  $ gitlab label promote -r myrepo --group mygroup --recreate`,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := LoadFromConfig(repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+		group := labelGroup
+		if group == "" {
+			group = path.Dir(from.Project.PathWithNamespace)
+		}
+
+		useAPI, err := from.Client.Labels.CanPromote(from.Project.ID, gitlab.Group(group))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if !useAPI && !promoteRecreate {
+			fmt.Fprintf(os.Stderr, "error: GitLab can't promote the labels to '%s', it needs GitLab 12.3 or newer and "+
+				"the group of the repository; use --recreate to create the group labels and delete the repository "+
+				"labels instead, which removes them from issues and merge requests\n", group)
+			os.Exit(1)
+		}
+		labels, err := from.Client.Labels.PlanPromote(from.Project.ID, promoteMatch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		result := &promoteOutput{
+			Repo:      from.Path(),
+			Group:     group,
+			DryRun:    dryRun,
+			Recreated: !useAPI,
+			Promoted:  labels,
+		}
+		if len(labels) == 0 {
+			fmt.Fprintf(messages(), "'%s': no labels to promote\n", from.Path())
+			result.Promoted = []*gogitlab.Label{}
+			mustRender(result, nil)
+			return
+		}
+		w := messages()
+		fmt.Fprintf(w, "Labels to promote from '%s' to group '%s':\n", from.Path(), group)
+		if !useAPI {
+			fmt.Fprintln(w, "(recreated in the group, they will be removed from issues and merge requests)")
+		}
+		for _, l := range labels {
+			fmt.Fprintf(w, "  promote '%s' (%s)\n", l.Name, l.Color)
+		}
+		if dryRun {
+			fmt.Fprintln(w, "Dry run, nothing was changed.")
+			mustRender(result, nil)
+			return
+		}
+		if !confirm(fmt.Sprintf("Promote %d label(s) and remove them from '%s'?", len(labels), from.Path())) {
+			fmt.Fprintf(os.Stderr, "error: aborted, nothing was changed\n")
+			os.Exit(1)
+		}
+		if err := from.Client.Labels.Promote(from.Project.ID, gitlab.Group(group), labels, promoteRecreate); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		mustRender(result, func(w io.Writer) {
+			fmt.Fprintf(w, "%d promoted\n", len(labels))
+		})
+	},
+}
+
+// promoteOutput is the --output of label promote.
+type promoteOutput struct {
+	Repo   string `json:"repo"`
+	Group  string `json:"group"`
+	DryRun bool   `json:"dry_run"`
+	// Recreated is true if the labels are recreated in the group,
+	// instead of promoted with GitLab's promote API.
+	Recreated bool              `json:"recreated"`
+	Promoted  []*gogitlab.Label `json:"promoted"`
+}
+
+func init() {
+	labelCmd.AddCommand(labelPromoteCmd)
+
+	labelPromoteCmd.Flags().StringVar(&promoteMatch, "match", "", "Label name to match, as a Go regex (https://golang.org/pkg/regexp/syntax)")
+	labelPromoteCmd.Flags().BoolVar(&promoteRecreate, "recreate", false, "Create the group labels and delete the repository labels if GitLab can't promote them, which removes them from issues and merge requests")
+}
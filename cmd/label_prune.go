@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var unusedSince string
+
+var labelPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the unused labels of a repository",
+	Long: `Delete the labels of a repository that no issue or merge request has, or,
+with --unused-since, that weren't used for that long: the last update of an
+issue or merge request that has them is older.
+
+A label is used if an issue or merge request has it, whether open, closed or
+merged. The labels of open issues or merge requests are never deleted, nor
+are the ones of issues or merge requests whose last update is unknown.
+
+The labels to delete are printed and you are asked to confirm, unless --yes
+is given. Use --dry-run to only print them. See 'label stats' for how much
+every label is used.`,
+	Example: `  $ gitlab label prune -r myrepo --dry-run
+  $ gitlab label prune -r myrepo --unused-since 180d`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var since time.Time
+		if unusedSince != "" {
+			age, err := parseAge(unusedSince)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			since = time.Now().Add(-age)
+		}
+		r, err := loadLabelsRepo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+		changes, err := r.Client.Labels.PlanPrune(r.LabelsID(), since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if err := applyLabelChanges(r, changes); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+// parseAge parses a duration that can also be in days or weeks,
+// e.g. '180d' or '4w', besides the units of time.ParseDuration.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, suffix)); err == nil && strings.HasSuffix(s, suffix) && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration '%s', must be like 180d, 4w or 12h", s)
+	}
+	return d, nil
+}
+
+func init() {
+	labelCmd.AddCommand(labelPruneCmd)
+
+	labelPruneCmd.Flags().StringVar(&unusedSince, "unused-since", "", "Also delete the labels not used for this long, e.g. 180d (default only never used)")
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var labelStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how much the labels of a repository are used",
+	Long: `Show, for every label of a repository, the number of open and closed issues
+and merge requests that have it and when it was last used, that is the last
+update of one of them.
+
+Labels that no issue or merge request has are marked as unused, and labels
+whose names differ only in case or separators (e.g. 'type:bug' and 'Type/Bug')
+as similar, since they are likely duplicates. Use 'label migrate' to merge
+them and 'label prune' to delete the unused ones.
+
+It reads all the issues and merge requests, so it can take a while for big
+repositories.`,
+	Example: `  $ gitlab label stats -r myrepo
+  $ gitlab label stats -r myrepo --group mygroup -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := loadLabelsRepo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+		stats, err := r.Client.Labels.Stats(r.LabelsID())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		out := make([]*labelStatsOutput, len(stats))
+		unused, similar := 0, 0
+		for i, s := range stats {
+			out[i] = newLabelStatsOutput(s)
+			if s.Unused() {
+				unused++
+			}
+			if len(s.Similar) > 0 {
+				similar++
+			}
+		}
+		mustRender(out, func(w io.Writer) {
+			tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tOPEN ISSUES\tCLOSED ISSUES\tOPEN MRS\tCLOSED MRS\tLAST USED\tNOTE")
+			for _, s := range out {
+				var notes []string
+				if s.Unused {
+					notes = append(notes, "unused")
+				}
+				if len(s.Similar) > 0 {
+					notes = append(notes, "similar to '"+strings.Join(s.Similar, "', '")+"'")
+				}
+				lastUsed := orDash(s.LastUsed)
+				if s.LastUsed == "" && !s.Unused {
+					lastUsed = "unknown"
+				}
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", s.Name, s.OpenIssues, s.ClosedIssues,
+					s.OpenMergeRequests, s.ClosedMergeRequests, lastUsed, orDash(strings.Join(notes, ", ")))
+			}
+			tw.Flush()
+			fmt.Fprintf(w, "%d label(s), %d unused, %d similar to others\n", len(out), unused, similar)
+		})
+	},
+}
+
+// labelStatsOutput is the --output of label stats.
+type labelStatsOutput struct {
+	Name                string   `json:"name"`
+	Color               string   `json:"color"`
+	OpenIssues          int      `json:"open_issues"`
+	ClosedIssues        int      `json:"closed_issues"`
+	OpenMergeRequests   int      `json:"open_merge_requests"`
+	ClosedMergeRequests int      `json:"closed_merge_requests"`
+	LastUsed            string   `json:"last_used,omitempty"`
+	Unused              bool     `json:"unused"`
+	Similar             []string `json:"similar,omitempty"`
+}
+
+func newLabelStatsOutput(s *gitlab.LabelStats) *labelStatsOutput {
+	out := &labelStatsOutput{
+		Name:                s.Label.Name,
+		Color:               s.Label.Color,
+		OpenIssues:          s.OpenIssues,
+		ClosedIssues:        s.ClosedIssues,
+		OpenMergeRequests:   s.OpenMergeRequests,
+		ClosedMergeRequests: s.ClosedMergeRequests,
+		Unused:              s.Unused(),
+		Similar:             s.Similar,
+	}
+	if s.LastUsed != nil {
+		out.LastUsed = s.LastUsed.Format(time.RFC3339)
+	}
+	return out
+}
+
+func init() {
+	labelCmd.AddCommand(labelStatsCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var manifestFile string
+var pruneLabels bool
+
+var labelSyncCmd = &cobra.Command{
+	Use:     "sync",
+	Aliases: []string{"s"},
+	Short:   "Sync the labels of a repository with a manifest",
+	Long: `Sync the labels of a repository with a manifest file.
+
+The manifest is a YAML or JSON file (based on the extension) that lists
+the labels the repository should have:
+
+  labels:
+  - name: bug
+    color: "#ff0000"
+    description: Something isn't working
+  - name: feature
+    color: "#00ff00"
+    aliases: [enhancement, "type:feature"]
+
+Missing labels are created and labels with a different color or description
+are updated. A label named like one of the aliases is renamed, if there's no
+label with the new name already. An empty color or description matches any.
+
+With --prune, the labels that are not in the manifest are deleted.`,
+	Example: `  $ gitlab label sync -r myrepo -f labels.yml
+  $ gitlab label sync -r myrepo -f labels.json --prune --dry-run
+  $ gitlab label sync -U https://gitlab.com -t <TOKEN> --group mygroup -f labels.yml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if manifestFile == "" {
+			fmt.Fprintf(os.Stderr, "error: no manifest file given\n")
+			os.Exit(1)
+		}
+		specs, err := readLabelManifest(manifestFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		to, err := loadLabelsRepo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
+			os.Exit(1)
+		}
+
+		changes, err := to.Client.Labels.PlanSync(to.LabelsID(), specs, pruneLabels)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if err := applyLabelChanges(to, changes); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	labelCmd.AddCommand(labelSyncCmd)
+
+	labelSyncCmd.Flags().StringVarP(&manifestFile, "file", "f", "", "Manifest file (YAML or JSON, '-' for stdin)")
+	labelSyncCmd.Flags().BoolVar(&pruneLabels, "prune", false, "Delete the labels that are not in the manifest")
+}
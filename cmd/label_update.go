@@ -21,23 +21,30 @@ var labelUpdateCmd = &cobra.Command{
 
 The --match flag is required and is a Go regex that will be used to match the label
 name. At least one of --name, --color or --description is required to update the label(s).`,
-	Example: `  $ gitlab label update -r myrepo --match "(.*):(.*)" --name "${1}/${2}"`,
+	Example: `  $ gitlab label update -r myrepo --match "(.*):(.*)" --name "${1}/${2}"
+  $ gitlab label update -r myrepo --match "^type" --color "#ff0000" --dry-run
+  $ gitlab label update -r myrepo --group mygroup --match "^bug$" --color "#ff0000"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
 			to  *Repo
 			err error
 		)
-		if to, err = LoadFromConfig(repo); err != nil {
+		if to, err = loadLabelsRepo(); err != nil {
 			fmt.Fprintf(os.Stderr, "error: invalid repository: %v\n", err.Error())
 			os.Exit(1)
 		}
 
-		if err := to.Client.Labels.UpdateWithRegex(to.Project.ID, &gogitlab.UpdateLabelOptions{
+		changes, err := to.Client.Labels.PlanUpdateWithRegex(to.LabelsID(), &gogitlab.UpdateLabelOptions{
 			Name:        &matchLabel,
 			NewName:     &replaceLabel,
 			Color:       &colorLabel,
 			Description: &descriptionLabel,
-		}); err != nil {
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
+			os.Exit(1)
+		}
+		if err := applyLabelChanges(to, changes); err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err.Error())
 			os.Exit(1)
 		}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var mrCmd = &cobra.Command{
+	Use:     "mr",
+	Aliases: []string{"merge-request"},
+	Short:   "Merge request actions",
+	Long: `Perform actions on the merge requests of the repository given by -r or -U.
+
+Merge requests are referred to by their number in the repository, e.g. 12 or !12.`,
+}
+
+func init() {
+	RootCmd.AddCommand(mrCmd)
+}
+
+// mrIID returns the merge request number given as the only argument, or exits.
+func mrIID(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "error: no merge request number given\n")
+		os.Exit(1)
+	}
+	iid, err := strconv.Atoi(strings.TrimPrefix(args[0], "!"))
+	if err != nil || iid < 1 {
+		fmt.Fprintf(os.Stderr, "error: invalid merge request number '%s'\n", args[0])
+		os.Exit(1)
+	}
+	return iid
+}
+
+// mrOutput is the --output of a merge request.
+type mrOutput struct {
+	IID                       int               `json:"iid"`
+	Title                     string            `json:"title"`
+	State                     string            `json:"state"`
+	SourceBranch              string            `json:"source_branch"`
+	TargetBranch              string            `json:"target_branch"`
+	Labels                    []string          `json:"labels"`
+	Milestone                 string            `json:"milestone,omitempty"`
+	Assignee                  string            `json:"assignee,omitempty"`
+	Author                    string            `json:"author,omitempty"`
+	WorkInProgress            bool              `json:"work_in_progress"`
+	MergeStatus               string            `json:"merge_status,omitempty"`
+	MergeWhenPipelineSucceeds bool              `json:"merge_when_pipeline_succeeds"`
+	Squash                    bool              `json:"squash"`
+	Pipeline                  *gitlab.Pipeline  `json:"pipeline,omitempty"`
+	Changes                   *gitlab.DiffStats `json:"changes,omitempty"`
+	Description               string            `json:"description,omitempty"`
+	WebURL                    string            `json:"web_url,omitempty"`
+	CreatedAt                 *time.Time        `json:"created_at,omitempty"`
+	UpdatedAt                 *time.Time        `json:"updated_at,omitempty"`
+}
+
+func newMROutput(mr *gitlab.MergeRequest) *mrOutput {
+	out := &mrOutput{
+		IID:                       mr.IID,
+		Title:                     mr.Title,
+		State:                     mr.State,
+		SourceBranch:              mr.SourceBranch,
+		TargetBranch:              mr.TargetBranch,
+		Labels:                    mr.Labels,
+		WorkInProgress:            mr.WorkInProgress,
+		MergeStatus:               mr.MergeStatus,
+		MergeWhenPipelineSucceeds: mr.MergeWhenPipelineSucceeds,
+		Squash:                    mr.Squash,
+		Pipeline:                  mr.Pipeline,
+		Description:               mr.Description,
+		WebURL:                    mr.WebURL,
+		CreatedAt:                 mr.CreatedAt,
+		UpdatedAt:                 mr.UpdatedAt,
+	}
+	if out.Labels == nil {
+		out.Labels = []string{}
+	}
+	if mr.Milestone != nil {
+		out.Milestone = mr.Milestone.Title
+	}
+	if mr.Assignee != nil {
+		out.Assignee = mr.Assignee.Username
+	}
+	if mr.Author != nil {
+		out.Author = mr.Author.Username
+	}
+	return out
+}
+
+// printMR writes the merge request for humans, as 'mr view' shows it.
+func printMR(w io.Writer, mr *mrOutput) {
+	state := mr.State
+	if mr.WorkInProgress {
+		state += ", draft"
+	}
+	fmt.Fprintf(w, "!%d %s (%s)\n", mr.IID, mr.Title, state)
+	fmt.Fprintf(w, "  branches: %s → %s\n", mr.SourceBranch, mr.TargetBranch)
+	var pipeline, changes, merge string
+	if mr.Pipeline != nil {
+		pipeline = mr.Pipeline.Status
+	}
+	if mr.Changes != nil {
+		changes = fmt.Sprintf("%d file(s), +%d -%d", mr.Changes.Files, mr.Changes.Additions, mr.Changes.Deletions)
+	}
+	if mr.MergeWhenPipelineSucceeds {
+		merge = "when the pipeline succeeds"
+	}
+	for _, f := range [][2]string{
+		{"author", mr.Author},
+		{"assignee", mr.Assignee},
+		{"labels", strings.Join(mr.Labels, ", ")},
+		{"milestone", mr.Milestone},
+		{"pipeline", pipeline},
+		{"changes", changes},
+		{"merge status", strings.Replace(mr.MergeStatus, "_", " ", -1)},
+		{"merge", merge},
+		{"url", mr.WebURL},
+	} {
+		if f[1] != "" {
+			fmt.Fprintf(w, "  %s: %s\n", f[0], f[1])
+		}
+	}
+	if mr.CreatedAt != nil {
+		fmt.Fprintf(w, "  created: %s\n", mr.CreatedAt.Format(time.RFC3339))
+	}
+	if mr.UpdatedAt != nil {
+		fmt.Fprintf(w, "  updated: %s\n", mr.UpdatedAt.Format(time.RFC3339))
+	}
+	if mr.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimRight(mr.Description, "\n"))
+	}
+}
+
+// currentBranch returns the git branch checked out in the current directory.
+func currentBranch() (string, error) {
+	out, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		if e, ok := err.(*exec.ExitError); ok {
+			if len(e.Stderr) > 0 {
+				return "", fmt.Errorf("failed to get the current git branch: %s", strings.TrimSpace(string(e.Stderr)))
+			}
+			return "", fmt.Errorf("no git branch is checked out (detached HEAD)")
+		}
+		return "", fmt.Errorf("failed to get the current git branch: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var mrApproveCmd = &cobra.Command{
+	Use:   "approve <mr>",
+	Short: "Approve a merge request",
+	Long: `Approve a merge request as the user of the credentials of the repository.
+Approvals need GitLab 13.2 or newer, or GitLab EE.`,
+	Example: `  $ gitlab-cli mr approve -r myrepo 12`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := mrIID(args)
+		r := loadIssueRepo()
+		if err := r.Client.MergeRequests.Approve(r.Project.ID, iid); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to approve merge request !%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		mustRender(&approvalOutput{IID: iid, Approved: true}, func(w io.Writer) {
+			fmt.Fprintf(w, "Approved merge request !%d\n", iid)
+		})
+	},
+}
+
+// approvalOutput is the --output of mr approve and mr unapprove.
+type approvalOutput struct {
+	IID      int  `json:"iid"`
+	Approved bool `json:"approved"`
+}
+
+func init() {
+	mrCmd.AddCommand(mrApproveCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var mrCloseCmd = &cobra.Command{
+	Use:     "close <mr>",
+	Short:   "Close a merge request without merging it",
+	Example: `  $ gitlab-cli mr close -r myrepo 12`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := mrIID(args)
+		r := loadIssueRepo()
+		mr, err := r.Client.MergeRequests.Close(r.Project.ID, iid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to close merge request !%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		mustRender(newMROutput(mr), func(w io.Writer) {
+			fmt.Fprintf(w, "Closed merge request !%d %s\n", mr.IID, mr.Title)
+		})
+	},
+}
+
+func init() {
+	mrCmd.AddCommand(mrCloseCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mrSource, mrTarget       string
+	mrRemoveSource, mrSquash bool
+)
+
+var mrCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a merge request",
+	Long: `Create a merge request in a repository, from the git branch checked out in
+the current directory, or --source, to the default branch of the repository,
+or --target. The source branch must be pushed first.
+
+The description is read from --body, or from --body-file ('-' for stdin).
+Without --title, or with --edit, the title and description are written in
+$VISUAL or $EDITOR.`,
+	Example: `  $ gitlab-cli mr create -r myrepo --title "Fix crash on start" --label bug
+  $ gitlab-cli mr create -r myrepo --source fix-crash --target develop --squash
+  $ gitlab-cli mr create -r myrepo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		source := mrSource
+		if source == "" {
+			var err error
+			if source, err = currentBranch(); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v, use --source\n", err)
+				os.Exit(1)
+			}
+		}
+		r := loadIssueRepo()
+		target := mrTarget
+		if target == "" {
+			if target = r.Project.DefaultBranch; target == "" {
+				fmt.Fprintf(os.Stderr, "error: '%s' has no default branch, use --target\n", r.Path())
+				os.Exit(1)
+			}
+		}
+		issueOpts, err := issueOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		opts := &gitlab.MergeRequestOptions{IssueOptions: *issueOpts}
+		if cmd.Flags().Changed("remove-source-branch") {
+			opts.RemoveSourceBranch = &mrRemoveSource
+		}
+		if cmd.Flags().Changed("squash") {
+			opts.Squash = &mrSquash
+		}
+		if opts.Title == nil || issueEdit {
+			var title, body string
+			if opts.Title != nil {
+				title = *opts.Title
+			}
+			if opts.Description != nil {
+				body = *opts.Description
+			}
+			if title, body, err = editText("merge request", title, body); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Title, opts.Description = &title, &body
+		}
+		mr, err := r.Client.MergeRequests.Create(r.Project.ID, source, target, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to create the merge request: %v\n", err)
+			os.Exit(1)
+		}
+		out := newMROutput(mr)
+		mustRender(out, func(w io.Writer) {
+			fmt.Fprintf(w, "Created merge request !%d in '%s' from %s to %s\n", out.IID, r.Path(), source, target)
+			if out.WebURL != "" {
+				fmt.Fprintln(w, out.WebURL)
+			}
+		})
+	},
+}
+
+func init() {
+	mrCmd.AddCommand(mrCreateCmd)
+	addIssueFlags(mrCreateCmd, "merge request")
+
+	mrCreateCmd.Flags().StringVar(&mrSource, "source", "", "Branch to merge (default the current git branch)")
+	mrCreateCmd.Flags().StringVar(&mrTarget, "target", "", "Branch to merge into (default the default branch of the repository)")
+	mrCreateCmd.Flags().BoolVarP(&mrRemoveSource, "remove-source-branch", "d", false, "Remove the source branch once merged")
+	mrCreateCmd.Flags().BoolVar(&mrSquash, "squash", false, "Squash the commits into one when merged")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var mrListOpts gitlab.ListMergeRequestsOptions
+
+var mrListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the merge requests of a repository",
+	Long: `List the merge requests of a repository, newest first. Only the open merge
+requests are listed, unless --state is given.`,
+	Example: `  $ gitlab-cli mr list -r myrepo
+  $ gitlab-cli mr list -r myrepo --label bug --assignee my_user --target master
+  $ gitlab-cli mr list -r myrepo --state merged --search crash`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := mrListOpts
+		switch opts.State {
+		case gitlab.MergeRequestOpened, gitlab.MergeRequestClosed, gitlab.MergeRequestMerged:
+		case "all":
+			opts.State = ""
+		default:
+			fmt.Fprintf(os.Stderr, "error: invalid state '%s', must be opened, closed, merged or all\n", opts.State)
+			os.Exit(1)
+		}
+		r := loadIssueRepo()
+		mrs, err := r.Client.MergeRequests.List(r.Project.ID, &opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		out := make([]*mrOutput, len(mrs))
+		for i, mr := range mrs {
+			out[i] = newMROutput(mr)
+		}
+		mustRender(out, func(w io.Writer) {
+			if len(out) == 0 {
+				fmt.Fprintln(w, "No merge requests found")
+				return
+			}
+			tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "MR\tTITLE\tSTATE\tBRANCHES\tLABELS\tASSIGNEE")
+			for _, mr := range out {
+				fmt.Fprintf(tw, "!%d\t%s\t%s\t%s → %s\t%s\t%s\n", mr.IID, mr.Title, mr.State, mr.SourceBranch,
+					mr.TargetBranch, orDash(strings.Join(mr.Labels, ",")), orDash(mr.Assignee))
+			}
+			tw.Flush()
+		})
+	},
+}
+
+func init() {
+	mrCmd.AddCommand(mrListCmd)
+
+	mrListCmd.Flags().StringVar(&mrListOpts.State, "state", gitlab.MergeRequestOpened, "State of the merge requests: opened, closed, merged or all")
+	mrListCmd.Flags().StringSliceVarP(&mrListOpts.Labels, "label", "l", nil, "Only merge requests with all these labels")
+	mrListCmd.Flags().StringVarP(&mrListOpts.Milestone, "milestone", "m", "", "Only merge requests in the milestone with this title")
+	mrListCmd.Flags().StringVarP(&mrListOpts.Assignee, "assignee", "a", "", "Only merge requests assigned to the user with this username")
+	mrListCmd.Flags().StringVarP(&mrListOpts.Search, "search", "s", "", "Only merge requests with this text in the title or description")
+	mrListCmd.Flags().StringVar(&mrListOpts.SourceBranch, "source", "", "Only merge requests from this branch")
+	mrListCmd.Flags().StringVar(&mrListOpts.TargetBranch, "target", "", "Only merge requests into this branch")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/clns/gitlab-cli/gitlab"
+	"github.com/spf13/cobra"
+)
+
+var mrMergeOpts gitlab.MergeOptions
+
+var mrMergeCmd = &cobra.Command{
+	Use:   "merge <mr>",
+	Short: "Merge a merge request",
+	Long: `Merge a merge request into its target branch.
+
+With --when-pipeline-succeeds, a merge request whose pipeline is running is
+merged once the pipeline succeeds instead of right away. Use --sha to only
+merge if the source branch is still at that commit.`,
+	Example: `  $ gitlab-cli mr merge -r myrepo 12
+  $ gitlab-cli mr merge -r myrepo 12 --when-pipeline-succeeds --squash --remove-source-branch`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := mrIID(args)
+		r := loadIssueRepo()
+		mr, err := r.Client.MergeRequests.Merge(r.Project.ID, iid, &mrMergeOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to merge merge request !%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		mustRender(newMROutput(mr), func(w io.Writer) {
+			switch {
+			case mr.State == gitlab.MergeRequestMerged:
+				fmt.Fprintf(w, "Merged merge request !%d %s into %s\n", mr.IID, mr.Title, mr.TargetBranch)
+			case mrMergeOpts.WhenPipelineSucceeds:
+				fmt.Fprintf(w, "Merge request !%d %s will be merged when the pipeline succeeds\n", mr.IID, mr.Title)
+			default:
+				fmt.Fprintf(w, "Merge request !%d %s is still %s\n", mr.IID, mr.Title, mr.State)
+			}
+		})
+	},
+}
+
+func init() {
+	mrCmd.AddCommand(mrMergeCmd)
+
+	mrMergeCmd.Flags().BoolVar(&mrMergeOpts.WhenPipelineSucceeds, "when-pipeline-succeeds", false, "Merge once the running pipeline succeeds")
+	mrMergeCmd.Flags().BoolVar(&mrMergeOpts.Squash, "squash", false, "Squash the commits into one")
+	mrMergeCmd.Flags().BoolVarP(&mrMergeOpts.RemoveSourceBranch, "remove-source-branch", "d", false, "Remove the source branch once merged")
+	mrMergeCmd.Flags().StringVar(&mrMergeOpts.Message, "message", "", "Message of the merge commit")
+	mrMergeCmd.Flags().StringVar(&mrMergeOpts.SHA, "sha", "", "Only merge if the head of the source branch is this commit")
+}
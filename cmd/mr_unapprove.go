@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var mrUnapproveCmd = &cobra.Command{
+	Use:     "unapprove <mr>",
+	Short:   "Remove your approval of a merge request",
+	Example: `  $ gitlab-cli mr unapprove -r myrepo 12`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := mrIID(args)
+		r := loadIssueRepo()
+		if err := r.Client.MergeRequests.Unapprove(r.Project.ID, iid); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to unapprove merge request !%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		mustRender(&approvalOutput{IID: iid}, func(w io.Writer) {
+			fmt.Fprintf(w, "Removed the approval of merge request !%d\n", iid)
+		})
+	},
+}
+
+func init() {
+	mrCmd.AddCommand(mrUnapproveCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var mrViewWeb bool
+
+var mrViewCmd = &cobra.Command{
+	Use:   "view <mr>",
+	Short: "Show a merge request",
+	Long: `Show a merge request, with the status of its pipeline and the number of
+files and lines it changes.`,
+	Example: `  $ gitlab-cli mr view -r myrepo 12
+  $ gitlab-cli mr view -r myrepo 12 --web`,
+	Run: func(cmd *cobra.Command, args []string) {
+		iid := mrIID(args)
+		r := loadIssueRepo()
+		mr, err := r.Client.MergeRequests.Get(r.Project.ID, iid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if mrViewWeb && mr.WebURL != "" {
+			openBrowser(mr.WebURL)
+			return
+		}
+		out := newMROutput(mr)
+		if out.Changes, err = r.Client.MergeRequests.DiffStats(r.Project.ID, iid); err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to get the changes of merge request !%d: %v\n", iid, err)
+			os.Exit(1)
+		}
+		mustRender(out, func(w io.Writer) {
+			printMR(w, out)
+		})
+	},
+}
+
+func init() {
+	mrCmd.AddCommand(mrViewCmd)
+
+	mrViewCmd.Flags().BoolVarP(&mrViewWeb, "web", "w", false, "Open the merge request in the browser")
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats for --output.
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputYAML     = "yaml"
+	outputTemplate = "template="
+)
+
+var output string
+
+func init() {
+	cobra.OnInitialize(checkOutput)
+
+	RootCmd.PersistentFlags().StringVarP(&output, "output", "o", outputTable, "output format: table, json, yaml or template=<Go template>")
+}
+
+// checkOutput exits if --output is not a valid format,
+// before the command gets to change anything.
+func checkOutput() {
+	if output == outputTable || output == outputJSON || output == outputYAML {
+		return
+	}
+	if strings.HasPrefix(output, outputTemplate) {
+		if _, err := template.New("output").Parse(strings.TrimPrefix(output, outputTemplate)); err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid output template: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "error: unknown output format '%s', must be one of: table, json, yaml, template=<Go template>\n", output)
+	os.Exit(1)
+}
+
+// messages returns where to print the messages meant for humans, like
+// the changes being made or questions: stdout for the table output and
+// stderr for the others, so that stdout only has what render writes.
+func messages() io.Writer {
+	if output == outputTable {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// render writes the result of a command to stdout in the --output format.
+// For the table format it calls table, if not nil, to write it the human
+// readable way. The other formats use the json names of the fields of v,
+// so e.g. a template for a list of labels is '{{range .}}{{.name}}{{end}}'.
+func render(v interface{}, table func(w io.Writer)) error {
+	if output == outputTable {
+		if table != nil {
+			table(os.Stdout)
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		_, err = fmt.Println(string(b))
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return err
+	}
+	if output == outputYAML {
+		b, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	tmpl, err := template.New("output").Parse(strings.TrimPrefix(output, outputTemplate))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(os.Stdout, generic)
+}
+
+// mustRender is render for the end of a command, it exits on errors.
+func mustRender(v interface{}, table func(w io.Writer)) {
+	if err := render(v, table); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
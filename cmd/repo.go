@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"encoding/json"
 	"net/url"
 
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
 
 	"os"
+	"time"
 
 	"github.com/clns/gitlab-cli/gitlab"
 	"github.com/howeyc/gopass"
+	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 	gogitlab "github.com/xanzy/go-gitlab"
 )
@@ -18,15 +23,94 @@ import (
 type Repo struct {
 	Client  *gitlab.Client
 	Project *gogitlab.Project
+	Group   string // group path, for repos that are a group instead of a project
 	Name    string
 	Url_    string `mapstructure:"url"`
 	URL     *url.URL
-	Token   string `mapstructure:"token"`
+	// Host is the name of the host from the 'hosts' section of the config
+	// file that the repo is on, with the url, credentials and settings of
+	// the repo, unless the repo has its own. The repo's url is then the
+	// host url with the path of Project_.
+	Host     string `mapstructure:"host"`
+	Project_ string `mapstructure:"project"`
+	Token    string `mapstructure:"token"`
+	// TokenSecret is the key of the token in the secret store,
+	// if it isn't in the config file.
+	TokenSecret string `mapstructure:"token_secret"`
+	// OAuthToken is the OAuth2 token from 'auth login', as JSON, or its
+	// key in the secret store, OAuthTokenSecret.
+	OAuthToken       string `mapstructure:"oauth_token"`
+	OAuthTokenSecret string `mapstructure:"oauth_token_secret"`
+
+	// oauthToken is the OAuth2 token to use, set by 'auth login'.
+	oauthToken *gitlab.OAuthToken
+	// section is the section of the config file the repo is in:
+	// 'repos', or 'hosts' for a host.
+	section string
+	// credentials is the key of the repo or host in the config file
+	// that the token is from, e.g. 'hosts.mygitlab'.
+	credentials string
+
+	ClientSettings `mapstructure:",squash"`
+}
+
+// ClientSettings are the settings of a repo for connecting to its
+// GitLab instance, besides the url and token. See gitlab.Options.
+type ClientSettings struct {
+	APIVersion   string            `mapstructure:"api_version"`
+	Retries      int               `mapstructure:"retries"`
+	RetryBackoff time.Duration     `mapstructure:"retry_backoff"`
+	Insecure     bool              `mapstructure:"insecure"`
+	CAFile       string            `mapstructure:"ca_file"`
+	CertFile     string            `mapstructure:"cert_file"`
+	KeyFile      string            `mapstructure:"key_file"`
+	Proxy        string            `mapstructure:"proxy"`
+	NoProxy      []string          `mapstructure:"no_proxy"`
+	Headers      map[string]string `mapstructure:"headers"`
+	OAuth        oauthSettings     `mapstructure:"oauth"`
 }
 
+// oauthSettings is the OAuth2 application to log in with, see 'auth login'.
+type oauthSettings struct {
+	ClientID     string   `mapstructure:"client_id" yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	ClientSecret string   `mapstructure:"client_secret" yaml:"client_secret,omitempty" json:"client_secret,omitempty"`
+	RedirectURI  string   `mapstructure:"redirect_uri" yaml:"redirect_uri,omitempty" json:"redirect_uri,omitempty"`
+	Scopes       []string `mapstructure:"scopes" yaml:"scopes,omitempty" json:"scopes,omitempty"`
+}
+
+// app returns the gitlab.OAuthApp, or nil if there's no application.
+func (s *oauthSettings) app() *gitlab.OAuthApp {
+	if s.ClientID == "" {
+		return nil
+	}
+	return &gitlab.OAuthApp{
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURI:  s.RedirectURI,
+		Scopes:       s.Scopes,
+	}
+}
+
+// repoMap is a repo or host as saved in the config file.
 type repoMap struct {
-	URL   string `mapstructure:"url"`
-	Token string `mapstructure:"token"`
+	URL              string            `mapstructure:"url" yaml:"url,omitempty"`
+	Host             string            `mapstructure:"host" yaml:"host,omitempty"`
+	Project          string            `mapstructure:"project" yaml:"project,omitempty"`
+	Token            string            `mapstructure:"token" yaml:"token,omitempty"`
+	TokenSecret      string            `mapstructure:"token_secret" yaml:"token_secret,omitempty"`
+	APIVersion       string            `mapstructure:"api_version" yaml:"api_version,omitempty"`
+	Retries          *int              `mapstructure:"retries" yaml:"retries,omitempty"`
+	RetryBackoff     *time.Duration    `mapstructure:"retry_backoff" yaml:"retry_backoff,omitempty"`
+	Insecure         bool              `mapstructure:"insecure" yaml:"insecure,omitempty"`
+	CAFile           string            `mapstructure:"ca_file" yaml:"ca_file,omitempty"`
+	CertFile         string            `mapstructure:"cert_file" yaml:"cert_file,omitempty"`
+	KeyFile          string            `mapstructure:"key_file" yaml:"key_file,omitempty"`
+	Proxy            string            `mapstructure:"proxy" yaml:"proxy,omitempty"`
+	NoProxy          []string          `mapstructure:"no_proxy" yaml:"no_proxy,omitempty"`
+	Headers          map[string]string `mapstructure:"headers" yaml:"headers,omitempty"`
+	OAuth            *oauthSettings    `mapstructure:"oauth" yaml:"oauth,omitempty"`
+	OAuthToken       string            `mapstructure:"oauth_token" yaml:"oauth_token,omitempty"`
+	OAuthTokenSecret string            `mapstructure:"oauth_token_secret" yaml:"oauth_token_secret,omitempty"`
 }
 
 func LoadFromConfig(namepath string) (*Repo, error) {
@@ -37,51 +121,752 @@ func LoadFromConfig(namepath string) (*Repo, error) {
 	return r, nil
 }
 
-func LoadFromConfigNoInit(namepath string) *Repo {
-	key := "repos." + namepath
+// LoadFromConfigRelativeTo is like LoadFromConfig, but if namepath is not
+// a repo name from the config file, it is considered a project path
+// (e.g. 'group/repo') on the same GitLab instance as base, and accessed
+// with the same credentials.
+func LoadFromConfigRelativeTo(namepath string, base *Repo) (*Repo, error) {
+	if base == nil || viper.IsSet("repos."+namepath) {
+		return LoadFromConfig(namepath)
+	}
+	u, _ := base.instanceURL()
+	u.Path += "/" + strings.Trim(namepath, "/")
+	// same instance and credentials, so the same client
 	r := &Repo{
-		Url_:  viper.GetString("_url"),
-		Token: viper.GetString("_token"),
+		Client:         base.Client,
+		Url_:           u.String(),
+		Token:          base.Token,
+		ClientSettings: base.ClientSettings,
+	}
+	if err := r.initialize(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadGroupFromConfig returns a repo for the GitLab group with the given
+// path, on the GitLab instance of the repo given by namepath (or -U),
+// accessed with its credentials. The path of the repo url is ignored.
+func LoadGroupFromConfig(namepath, group string) (*Repo, error) {
+	r := LoadFromConfigNoInit(namepath)
+	if err := r.parseURL(); err != nil {
+		return nil, err
+	}
+	if err := r.initializeClient(); err != nil {
+		return nil, err
+	}
+	group = strings.Trim(group, "/")
+	if _, _, err := r.Client.Groups.GetGroup(group); err != nil {
+		return nil, fmt.Errorf("failed to get GitLab group '%s': %v", group, err)
+	}
+	r.Group = group
+	return r, nil
+}
+
+func LoadFromConfigNoInit(namepath string) *Repo {
+	r, err := loadFromConfigNoInit(namepath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	return r
+}
+
+// loadFromConfigNoInit is like LoadFromConfigNoInit, but returns an
+// error instead of exiting if the repo's host can't be loaded.
+func loadFromConfigNoInit(namepath string) (*Repo, error) {
+	r := newRepoFromFlags()
+	if namepath == "" && r.Url_ == "" {
+		var remote string
+		if namepath, r.Url_, remote = remoteOrDefaultRepo(); remote != "" {
+			what := "'" + namepath + "'"
+			if namepath == "" {
+				what = r.Url_
+			}
+			fmt.Fprintf(os.Stderr, "Using repo %s from git remote '%s'\n", what, remote)
+		}
 	}
+	key := "repos." + namepath
 	if viper.IsSet(key) {
+		if host := viper.GetString(key + ".host"); host != "" {
+			if err := r.loadHost(host); err != nil {
+				return nil, fmt.Errorf("repo '%s': %v", namepath, err)
+			}
+		}
+		if hasCredentials(key) {
+			// the repo's own credentials, not mixed with the host's
+			r.Token, r.TokenSecret, r.OAuthToken, r.OAuthTokenSecret = viper.GetString("_token"), "", "", ""
+		}
 		viper.UnmarshalKey(key, r)
 		r.Name = namepath
-	} else if namepath != "" {
-		if r.URL, _ = url.Parse(r.Url_); r.URL != nil {
-			r.URL.Path = namepath
+		if r.Host == "" || hasCredentials(key) {
+			r.credentials = key
+		}
+		if r.Host != "" && !viper.IsSet(key+".url") {
+			r.Url_ = strings.TrimSuffix(r.Url_, "/") + "/" + strings.Trim(r.Project_, "/")
 		}
+	} else {
+		if namepath != "" {
+			if r.URL, _ = url.Parse(r.Url_); r.URL != nil {
+				r.URL.Path = namepath
+			}
+		}
+		// the credentials of the host the url is on, if not given
+		if host, project := findHost(r.Url_); host != "" && r.Token == "" && user == "" {
+			u := r.Url_
+			if err := r.loadHost(host); err != nil {
+				return nil, fmt.Errorf("repo '%s': %v", u, err)
+			}
+			r.Url_, r.Project_ = u, project
+		}
+	}
+	r.overrideFromFlags()
+	return r, nil
+}
+
+// LoadHostFromConfigNoInit returns the host with the given name from
+// the config file, or a new host with that name and the url and token
+// given by flags.
+func LoadHostFromConfigNoInit(name string) *Repo {
+	r := newRepoFromFlags()
+	r.section = "hosts"
+	if viper.IsSet("hosts." + name) {
+		viper.UnmarshalKey("hosts."+name, r)
+		r.credentials = "hosts." + name
 	}
+	r.Name = name
+	r.overrideFromFlags()
 	return r
 }
 
+// newRepoFromFlags returns a repo with the url, token and client
+// settings given by flags, to be overridden by the config file.
+func newRepoFromFlags() *Repo {
+	return &Repo{
+		Url_:  viper.GetString("_url"),
+		Token: viper.GetString("_token"),
+		ClientSettings: ClientSettings{
+			APIVersion:   viper.GetString("_api_version"),
+			Retries:      retries,
+			RetryBackoff: retryBackoff,
+			Insecure:     insecure,
+			CAFile:       caFile,
+			CertFile:     certFile,
+			KeyFile:      keyFile,
+			Proxy:        proxy,
+			NoProxy:      noProxy,
+		},
+	}
+}
+
+// loadHost sets the url, credentials and settings of the repo
+// from the host with the given name.
+func (r *Repo) loadHost(name string) error {
+	key := "hosts." + name
+	if !viper.IsSet(key) {
+		return fmt.Errorf("unknown host '%s', see 'config host ls'", name)
+	}
+	var rep repoMap
+	if err := viper.UnmarshalKey(key, &rep); err != nil {
+		return fmt.Errorf("invalid host '%s' in the config file: %v", name, err)
+	}
+	viper.UnmarshalKey(key, r)
+	r.Host = name
+	r.credentials = key
+	return nil
+}
+
+// hasCredentials returns true if the repo or host with the given
+// key in the config file has a token.
+func hasCredentials(key string) bool {
+	for _, k := range []string{"token", "token_secret", "oauth_token", "oauth_token_secret"} {
+		if viper.IsSet(key + "." + k) {
+			return true
+		}
+	}
+	return false
+}
+
+// findHost returns the name of the host from the config file that the
+// url is on, and the path of the url on the host, or "" if there's none.
+func findHost(rawurl string) (host, path string) {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return "", ""
+	}
+	var names []string
+	for name := range viper.GetStringMap("hosts") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h, err := url.Parse(viper.GetString("hosts." + name + ".url"))
+		if err != nil || !strings.EqualFold(h.Host, u.Host) {
+			continue
+		}
+		if prefix := strings.TrimSuffix(h.Path, "/") + "/"; strings.HasPrefix(u.Path, prefix) {
+			return name, strings.Trim(strings.TrimPrefix(u.Path, prefix), "/")
+		}
+	}
+	return "", ""
+}
+
+// overrideFromFlags sets the client settings given by flags. Unlike
+// the url, token and API version, these override the config file.
+func (s *ClientSettings) overrideFromFlags() {
+	flags := RootCmd.PersistentFlags()
+	if flags.Changed("retries") {
+		s.Retries = retries
+	}
+	if flags.Changed("retry-backoff") {
+		s.RetryBackoff = retryBackoff
+	}
+	if flags.Changed("insecure") {
+		s.Insecure = insecure
+	}
+	if flags.Changed("ca-file") {
+		s.CAFile = caFile
+	}
+	if flags.Changed("cert-file") {
+		s.CertFile = certFile
+	}
+	if flags.Changed("key-file") {
+		s.KeyFile = keyFile
+	}
+	if flags.Changed("proxy") {
+		s.Proxy = proxy
+	}
+	if flags.Changed("no-proxy") {
+		s.NoProxy = noProxy
+	}
+	if flags.Changed("header") {
+		s.Headers = make(map[string]string)
+		for _, h := range headers {
+			i := strings.Index(h, ":")
+			if i < 1 {
+				fmt.Fprintf(os.Stderr, "error: invalid header '%s', must be 'Name: value'\n", h)
+				os.Exit(1)
+			}
+			s.Headers[strings.TrimSpace(h[:i])] = strings.TrimSpace(h[i+1:])
+		}
+	}
+}
+
+// options returns the gitlab.Options for the settings.
+func (s *ClientSettings) options() (*gitlab.Options, error) {
+	opts := &gitlab.Options{
+		APIVersion:   s.APIVersion,
+		Concurrency:  concurrency,
+		Retries:      s.Retries,
+		RetryBackoff: s.RetryBackoff,
+		Insecure:     s.Insecure,
+		Proxy:        s.Proxy,
+		NoProxy:      s.NoProxy,
+		Header:       make(http.Header),
+	}
+	for k, v := range s.Headers {
+		opts.Header.Set(k, v)
+	}
+	for _, f := range []struct {
+		path string
+		opt  *string
+	}{
+		{s.CAFile, &opts.CAFile},
+		{s.CertFile, &opts.CertFile},
+		{s.KeyFile, &opts.KeyFile},
+	} {
+		var err error
+		if *f.opt, err = homedir.Expand(f.path); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
 func (r *Repo) String() string {
-	return fmt.Sprintf(`%s
+	name := r.Name
+	if r.isDefault() {
+		name += " (default)"
+	}
+	if r.Host != "" && r.section != "hosts" {
+		name += fmt.Sprintf("\n  host: %s\n  project: %s", r.Host, r.Project_)
+	}
+	s := fmt.Sprintf(`%s
   url: %s
-  token: %s`, r.Name, r.Url_, r.Token)
+  token: %s
+  api_version: %s
+  retries: %d
+  retry_backoff: %v`, name, r.Url_, maskSecret(r.Token), r.APIVersion, r.Retries, r.RetryBackoff)
+	if r.TokenSecret != "" {
+		s += "\n  token_secret: " + r.TokenSecret
+	}
+	if r.Insecure {
+		s += "\n  insecure: true"
+	}
+	for _, f := range [][2]string{
+		{"ca_file", r.CAFile},
+		{"cert_file", r.CertFile},
+		{"key_file", r.KeyFile},
+	} {
+		if f[1] != "" {
+			s += fmt.Sprintf("\n  %s: %s", f[0], f[1])
+		}
+	}
+	if r.OAuth.ClientID != "" {
+		s += "\n  oauth:\n    client_id: " + r.OAuth.ClientID
+		if r.OAuth.ClientSecret != "" {
+			s += "\n    client_secret: " + maskSecret(r.OAuth.ClientSecret)
+		}
+		if r.OAuth.RedirectURI != "" {
+			s += "\n    redirect_uri: " + r.OAuth.RedirectURI
+		}
+		if len(r.OAuth.Scopes) > 0 {
+			s += "\n    scopes: " + strings.Join(r.OAuth.Scopes, ", ")
+		}
+	}
+	if r.OAuthToken != "" {
+		s += "\n  oauth_token: " + maskSecret(r.OAuthToken)
+	}
+	if r.OAuthTokenSecret != "" {
+		s += "\n  oauth_token_secret: " + r.OAuthTokenSecret
+	}
+	if r.Proxy != "" {
+		s += "\n  proxy: " + maskURL(r.Proxy)
+	}
+	if len(r.NoProxy) > 0 {
+		s += "\n  no_proxy: " + strings.Join(r.NoProxy, ", ")
+	}
+	if len(r.Headers) > 0 {
+		s += "\n  headers:"
+		var names []string
+		for name := range r.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			s += fmt.Sprintf("\n    %s: %s", name, maskSecret(r.Headers[name]))
+		}
+	}
+	return s
+}
+
+// isDefault returns true if the repo is the default repo, used
+// when no repo is given.
+func (r *Repo) isDefault() bool {
+	return r.Name != "" && r.section == "" && r.Name == viper.GetString("default_repo")
+}
+
+// defaultRepo returns the name of the repo given by -r, or if neither
+// -r nor -U is given, of the saved repo of the git remote or else the
+// default repo.
+func defaultRepo() string {
+	if repo == "" && viper.GetString("_url") == "" {
+		name, _, _ := remoteOrDefaultRepo()
+		return name
+	}
+	return repo
+}
+
+// remoteOrDefaultRepo returns the repo to use when neither -r nor -U is
+// given: the saved repo of the git remote of the current directory, or
+// the url of its project on a saved host (see repoFromRemote), or else
+// the default repo. remote is the name of the git remote, if used.
+// It exits if the remote given by --remote isn't found.
+func remoteOrDefaultRepo() (name, rawurl, remote string) {
+	name, rawurl, remote, err := repoFromRemote(remoteName)
+	if err != nil {
+		if remoteName != "" {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return viper.GetString("default_repo"), "", ""
+	}
+	return name, rawurl, remote
+}
+
+// repoOutput is the --output of a repo.
+type repoOutput struct {
+	Name         string            `json:"name,omitempty"`
+	Default      bool              `json:"default,omitempty"`
+	URL          string            `json:"url"`
+	Host         string            `json:"host,omitempty"`
+	Token        string            `json:"token"`
+	TokenSecret  string            `json:"token_secret,omitempty"`
+	APIVersion   string            `json:"api_version,omitempty"`
+	Retries      int               `json:"retries"`
+	RetryBackoff string            `json:"retry_backoff"`
+	Insecure     bool              `json:"insecure,omitempty"`
+	CAFile       string            `json:"ca_file,omitempty"`
+	CertFile     string            `json:"cert_file,omitempty"`
+	KeyFile      string            `json:"key_file,omitempty"`
+	Proxy        string            `json:"proxy,omitempty"`
+	NoProxy      []string          `json:"no_proxy,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	OAuth        *oauthSettings    `json:"oauth,omitempty"`
+	OAuthToken   string            `json:"oauth_token,omitempty"`
+	OAuthSecret  string            `json:"oauth_token_secret,omitempty"`
+	Project      string            `json:"project,omitempty"`
+}
+
+func (r *Repo) output() *repoOutput {
+	out := &repoOutput{
+		Name:         r.Name,
+		Default:      r.isDefault(),
+		URL:          r.Url_,
+		Token:        maskSecret(r.Token),
+		TokenSecret:  r.TokenSecret,
+		APIVersion:   r.APIVersion,
+		Retries:      r.Retries,
+		RetryBackoff: r.RetryBackoff.String(),
+		Insecure:     r.Insecure,
+		CAFile:       r.CAFile,
+		CertFile:     r.CertFile,
+		KeyFile:      r.KeyFile,
+		Proxy:        maskURL(r.Proxy),
+		NoProxy:      r.NoProxy,
+	}
+	if len(r.Headers) > 0 {
+		out.Headers = make(map[string]string)
+		for name, value := range r.Headers {
+			out.Headers[name] = maskSecret(value)
+		}
+	}
+	if r.OAuth.ClientID != "" {
+		app := r.OAuth
+		app.ClientSecret = maskSecret(app.ClientSecret)
+		out.OAuth = &app
+	}
+	out.OAuthToken = maskSecret(r.OAuthToken)
+	out.OAuthSecret = r.OAuthTokenSecret
+	if r.section != "hosts" {
+		out.Host = r.Host
+		out.Project = r.Project_
+	}
+	if r.Project != nil {
+		out.Project = r.Project.PathWithNamespace
+	}
+	return out
 }
 
+// SaveToConfig saves the repo or host in the config file, with its token
+// in the secret store. A repo on a host is saved as the host and the path
+// of the project, and its token, if any, with the host.
 func (r *Repo) SaveToConfig() error {
 	if r.Name == "" {
 		return fmt.Errorf("cannot save to config without a name")
 	}
-	repos := make(map[string]*repoMap)
-	for name, _ := range viper.GetStringMap("repos") {
+	if r.Host != "" && r.section != "hosts" {
+		project := strings.Trim(strings.TrimPrefix(r.URL.Path, r.hostPath()), "/")
+		own := r.credentials == r.configKey()
+		err := updateConfig("repos", r.Name, func(rep *repoMap) error {
+			*rep = repoMap{Host: r.Host, Project: project}
+			if own {
+				return r.saveCredentials(rep, r.configKey())
+			}
+			return nil
+		})
+		if err != nil || own || (r.Token == "" && r.currentOAuthToken() == nil) {
+			return err
+		}
+		return updateConfig("hosts", r.Host, func(rep *repoMap) error {
+			return r.saveCredentials(rep, "hosts."+r.Host)
+		})
+	}
+
+	u := *r.URL
+	if r.section == "hosts" {
+		u, _ = r.instanceURL()
+	}
+	rep := &repoMap{
+		URL:        u.String(),
+		APIVersion: r.APIVersion,
+		Insecure:   r.Insecure,
+		CAFile:     r.CAFile,
+		CertFile:   r.CertFile,
+		KeyFile:    r.KeyFile,
+		Proxy:      r.Proxy,
+		NoProxy:    r.NoProxy,
+		Headers:    r.Headers,
+	}
+	// the defaults are not saved, so that they can change
+	if r.Retries != gitlab.DefaultRetries {
+		rep.Retries = &r.Retries
+	}
+	if r.RetryBackoff != gitlab.DefaultRetryBackoff {
+		rep.RetryBackoff = &r.RetryBackoff
+	}
+	if r.OAuth.ClientID != "" {
+		app := r.OAuth
+		rep.OAuth = &app
+	}
+	if err := r.saveCredentials(rep, r.configKey()); err != nil {
+		return err
+	}
+	return updateConfig(r.configSection(), r.Name, func(old *repoMap) error {
+		*old = *rep
+		return nil
+	})
+}
+
+// configSection returns the section of the config file of the repo.
+func (r *Repo) configSection() string {
+	if r.section == "" {
+		return "repos"
+	}
+	return r.section
+}
+
+// configKey returns the key of the repo in the config file.
+func (r *Repo) configKey() string {
+	return r.configSection() + "." + r.Name
+}
+
+// hostPath returns the path of the url of the host of the repo.
+func (r *Repo) hostPath() string {
+	return hostURLPath(r.Host)
+}
+
+// hostURLPath returns the path of the url of the host with the given
+// name, e.g. '/gitlab' for a GitLab instance that isn't at the root.
+func hostURLPath(name string) string {
+	if u, err := url.Parse(viper.GetString("hosts." + name + ".url")); err == nil {
+		return u.Path
+	}
+	return ""
+}
+
+// instanceURL returns the url of the GitLab instance of the repo, which
+// keeps the path of its host's url (e.g. https://example.com/gitlab), and
+// the path of the project on the instance.
+func (r *Repo) instanceURL() (instance url.URL, project string) {
+	instance = *r.URL
+	instance.RawQuery, instance.Fragment = "", ""
+	var prefix string
+	switch {
+	case r.section == "hosts":
+		prefix = r.URL.Path
+	case r.Host != "":
+		prefix = r.hostPath()
+	default:
+		if host, _ := findHost(r.URL.String()); host != "" {
+			prefix = hostURLPath(host)
+		}
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" && r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+		prefix = ""
+	}
+	instance.Path = prefix
+	return instance, strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+}
+
+// updateConfig calls update with the repo or host with the given name
+// from the given section of the config file, or a new one, and sets it
+// in the config. The config file still has to be saved.
+func updateConfig(section, name string, update func(*repoMap) error) error {
+	return editConfig(section, func(entries map[string]*repoMap) error {
+		rep := entries[name]
+		if rep == nil {
+			rep = &repoMap{}
+		}
+		if err := update(rep); err != nil {
+			return err
+		}
+		entries[name] = rep
+		return nil
+	})
+}
+
+// editConfig calls edit with the repos or hosts from the given section
+// of the config file, by name, and sets them in the config, e.g. to add
+// or remove some. The config file still has to be saved.
+func editConfig(section string, edit func(map[string]*repoMap) error) error {
+	entries := make(map[string]*repoMap)
+	for n := range viper.GetStringMap(section) {
 		var rep *repoMap
-		if err := viper.UnmarshalKey("repos."+name, &rep); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		if err := viper.UnmarshalKey(section+"."+n, &rep); err != nil {
+			return fmt.Errorf("invalid %s '%s' in the config file: %v", section, n, err)
 		}
-		repos[name] = rep
+		entries[n] = rep
+	}
+	if err := edit(entries); err != nil {
+		return err
+	}
+	viper.Set(section, entries)
+	return nil
+}
+
+// saveCredentials saves the token of the repo in rep, the repo or host
+// with the given key in the config file: the private token if it has one,
+// its OAuth2 token otherwise.
+func (r *Repo) saveCredentials(rep *repoMap, key string) error {
+	rep.Token, rep.TokenSecret = "", ""
+	rep.OAuthToken, rep.OAuthTokenSecret = "", ""
+	if err := r.saveToken(rep, key); err != nil {
+		return err
+	}
+	if tok := r.currentOAuthToken(); tok != nil && r.Token == "" {
+		return r.saveOAuthToken(rep, key, tok)
+	}
+	return nil
+}
+
+// saveToken saves the token of the repo in the secret store and its
+// key in rep, or the token itself in rep with the plain store.
+func (r *Repo) saveToken(rep *repoMap, key string) error {
+	store, err := getSecretStore()
+	if err != nil {
+		return err
+	}
+	if store == nil || r.Token == "" {
+		rep.Token = r.Token
+		return nil
 	}
-	repos[r.Name] = &repoMap{
-		URL:   r.URL.String(),
-		Token: r.Token,
+	if err := store.Set(key+".token", r.Token); err != nil {
+		return err
 	}
-	viper.Set("repos", repos)
+	rep.TokenSecret = key + ".token"
+	return nil
+}
 
+// saveOAuthToken saves the OAuth2 token in the secret store and its key
+// in rep, or the token itself in rep with the plain store.
+func (r *Repo) saveOAuthToken(rep *repoMap, key string, tok *gitlab.OAuthToken) error {
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	store, err := getSecretStore()
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		rep.OAuthToken = string(b)
+		return nil
+	}
+	if err := store.Set(key+".oauth_token", string(b)); err != nil {
+		return err
+	}
+	rep.OAuthTokenSecret = key + ".oauth_token"
 	return nil
 }
 
+// currentOAuthToken returns the OAuth2 token the client uses, that may
+// have been refreshed, or the one from 'auth login' if there's no client.
+func (r *Repo) currentOAuthToken() *gitlab.OAuthToken {
+	if r.Client != nil {
+		return r.Client.OAuthToken()
+	}
+	return r.oauthToken
+}
+
+// loadOAuthToken returns the OAuth2 token of the repo, from the config
+// file or the secret store, or nil if it has none.
+func (r *Repo) loadOAuthToken() (*gitlab.OAuthToken, error) {
+	if r.oauthToken != nil {
+		return r.oauthToken, nil
+	}
+	s := r.OAuthToken
+	if s == "" && r.OAuthTokenSecret != "" {
+		store, err := getSecretStore()
+		if err != nil {
+			return nil, err
+		}
+		if store == nil {
+			return nil, fmt.Errorf("the OAuth token is in a secret store, but secret_store is '%s'", storePlain)
+		}
+		if s, err = store.Get(r.OAuthTokenSecret); err != nil {
+			return nil, err
+		}
+	}
+	if s == "" {
+		return nil, nil
+	}
+	var tok gitlab.OAuthToken
+	if err := json.Unmarshal([]byte(s), &tok); err != nil {
+		return nil, fmt.Errorf("invalid OAuth token, log in again: %v", err)
+	}
+	r.oauthToken = &tok
+	return r.oauthToken, nil
+}
+
+// onOAuthRefresh saves the OAuth2 token after the client refreshed it,
+// where the old one was, since the old refresh token can't be used again.
+func (r *Repo) onOAuthRefresh(tok *gitlab.OAuthToken) {
+	b, err := json.Marshal(tok)
+	if err == nil {
+		if r.OAuthTokenSecret != "" {
+			err = r.saveRefreshedSecret(string(b))
+		} else if r.OAuthToken != "" && r.credentials != "" {
+			err = r.saveRefreshedPlain(string(b))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save the refreshed OAuth token of '%s', log in again if it fails next time: %v\n", r.Name, err)
+	}
+}
+
+func (r *Repo) saveRefreshedSecret(tok string) error {
+	store, err := getSecretStore()
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("the OAuth token is in a secret store, but secret_store is '%s'", storePlain)
+	}
+	return store.Set(r.OAuthTokenSecret, tok)
+}
+
+func (r *Repo) saveRefreshedPlain(tok string) error {
+	i := strings.Index(r.credentials, ".")
+	err := updateConfig(r.credentials[:i], r.credentials[i+1:], func(rep *repoMap) error {
+		rep.OAuthToken = tok
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.OAuthToken = tok
+	return SaveViperConfig()
+}
+
+// LabelsID returns the id to manage the labels of the repo with:
+// the group for group repos, the project otherwise.
+func (r *Repo) LabelsID() interface{} {
+	if r.Group != "" {
+		return gitlab.Group(r.Group)
+	}
+	return r.Project.ID
+}
+
+// Path returns the path of the group or project of the repo.
+func (r *Repo) Path() string {
+	if r.Group != "" {
+		return r.Group
+	}
+	return r.Project.PathWithNamespace
+}
+
 func (r *Repo) initialize() error {
+	if err := r.parseURL(); err != nil {
+		return err
+	}
+	if _, project := r.instanceURL(); !strings.Contains(project, "/") {
+		return fmt.Errorf("invalid or no repo path specified")
+	}
+	if r.Client == nil {
+		if err := r.initializeClient(); err != nil {
+			return err
+		}
+	}
+	var err error
+	if r.Project, err = r.project(); err != nil {
+		return fmt.Errorf("failed to get GitLab project '%s': %v", r.URL, err)
+	}
+	return nil
+}
+
+func (r *Repo) parseURL() error {
 	var err error
 	r.URL, err = url.Parse(r.Url_)
 	if err != nil {
@@ -90,35 +875,65 @@ func (r *Repo) initialize() error {
 	if r.URL.String() == "" {
 		return fmt.Errorf("empty repo url")
 	}
-	if r.URL.Path == "" || strings.Index(r.URL.Path, "/") == -1 {
-		return fmt.Errorf("invalid or no repo path specified")
-	}
+	return nil
+}
+
+func (r *Repo) initializeClient() error {
+	var err error
 	if r.Client, err = r.client(); err != nil {
 		return fmt.Errorf("failed to get GitLab client for repo '%s': %v", r.URL, err)
 	}
 	r.Token = r.Client.Token
-	if r.Project, err = r.project(); err != nil {
-		return fmt.Errorf("failed to get GitLab project '%s': %v", r.URL, err)
-	}
+	r.APIVersion = r.Client.APIVersion
 	return nil
 }
 
 func (r *Repo) client() (*gitlab.Client, error) {
-	u := *r.URL
-	u.Path = ""
+	u, _ := r.instanceURL()
+	opts, err := r.options()
+	if err != nil {
+		return nil, err
+	}
+	if r.Token == "" && r.TokenSecret != "" {
+		store, err := getSecretStore()
+		if err != nil {
+			return nil, err
+		}
+		if store == nil {
+			return nil, fmt.Errorf("the token is in a secret store, but secret_store is '%s'", storePlain)
+		}
+		if r.Token, err = store.Get(r.TokenSecret); err != nil {
+			return nil, err
+		}
+	}
 	if r.Token == "" && user != "" {
 		if password == "" {
-			fmt.Print("Password: ")
+			fmt.Fprint(os.Stderr, "Password: ")
 			pwd, _ := gopass.GetPasswdMasked()
 			password = string(pwd)
 		}
-		return gitlab.NewClientForUser(&u, user, password)
+		opts.OAuth = &gitlab.OAuth{App: r.OAuth.app()}
+		return gitlab.NewClientForUser(&u, user, password, opts)
+	}
+	if r.Token == "" {
+		tok, err := r.loadOAuthToken()
+		if err != nil {
+			return nil, err
+		}
+		if tok != nil {
+			opts.OAuth = &gitlab.OAuth{
+				App:       r.OAuth.app(),
+				Token:     tok,
+				OnRefresh: r.onOAuthRefresh,
+			}
+		}
 	}
-	return gitlab.NewClient(&u, r.Token)
+	return gitlab.NewClient(&u, r.Token, opts)
 }
 
 func (r *Repo) project() (*gogitlab.Project, error) {
-	proj, err := r.Client.Projects.ByPath(r.URL.Path)
+	_, project := r.instanceURL()
+	proj, err := r.Client.Projects.ByPath(project)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestRepo_InstanceURL(t *testing.T) {
+	viper.Set("hosts", map[string]interface{}{
+		"sub": map[string]interface{}{"url": "https://example.com/gitlab"},
+	})
+	defer viper.Set("hosts", map[string]interface{}{})
+
+	tests := []struct {
+		repo              *Repo
+		instance, project string
+	}{
+		{&Repo{Url_: "https://gitlab.com/group/sub/repo"}, "https://gitlab.com", "group/sub/repo"},
+		{&Repo{Url_: "https://example.com/gitlab/group/repo", Host: "sub"}, "https://example.com/gitlab", "group/repo"},
+		// a url on a saved host, given with -U
+		{&Repo{Url_: "https://example.com/gitlab/group/repo?x=1"}, "https://example.com/gitlab", "group/repo"},
+		{&Repo{Url_: "https://example.com/other/repo"}, "https://example.com", "other/repo"},
+		{&Repo{Url_: "https://example.com/gitlab/", section: "hosts"}, "https://example.com/gitlab", ""},
+	}
+	for _, test := range tests {
+		var err error
+		if test.repo.URL, err = url.Parse(test.repo.Url_); err != nil {
+			t.Fatal(err)
+		}
+		instance, project := test.repo.instanceURL()
+		if instance.String() != test.instance || project != test.project {
+			t.Errorf("%s: expecting %s and '%s', got %s and '%s'", test.repo.Url_, test.instance, test.project,
+				instance.String(), project)
+		}
+	}
+}
+
+func TestLoadFromConfigNoInit_Host(t *testing.T) {
+	viper.Set("hosts", map[string]interface{}{
+		"work": map[string]interface{}{
+			"url":          "https://gitlab.example.com/gitlab",
+			"token_secret": "hosts.work.token",
+			"api_version":  "v3",
+		},
+	})
+	viper.Set("repos", map[string]interface{}{
+		"onhost": map[string]interface{}{"host": "work", "project": "group/repo"},
+		"own": map[string]interface{}{
+			"host":        "work",
+			"project":     "group/other",
+			"oauth_token": "secret",
+		},
+		"unknown": map[string]interface{}{"host": "missing", "project": "group/repo"},
+	})
+	defer viper.Set("hosts", map[string]interface{}{})
+	defer viper.Set("repos", map[string]interface{}{})
+
+	tests := []struct {
+		name                    string
+		url, credentials        string
+		tokenSecret, oauthToken string
+		apiVersion              string
+	}{
+		{"onhost", "https://gitlab.example.com/gitlab/group/repo", "hosts.work", "hosts.work.token", "", "v3"},
+		// the repo's own token, without the host's
+		{"own", "https://gitlab.example.com/gitlab/group/other", "repos.own", "", "secret", "v3"},
+	}
+	for _, test := range tests {
+		r, err := loadFromConfigNoInit(test.name)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if r.Url_ != test.url || r.credentials != test.credentials || r.TokenSecret != test.tokenSecret ||
+			r.OAuthToken != test.oauthToken || r.APIVersion != test.apiVersion {
+			t.Errorf("%s: expecting %s, %s, '%s', '%s' and %s, got %s, %s, '%s', '%s' and %s", test.name,
+				test.url, test.credentials, test.tokenSecret, test.oauthToken, test.apiVersion,
+				r.Url_, r.credentials, r.TokenSecret, r.OAuthToken, r.APIVersion)
+		}
+	}
+	if r, err := loadFromConfigNoInit("unknown"); err == nil || !strings.Contains(err.Error(), "unknown host 'missing'") {
+		t.Errorf("expecting error for an unknown host, got %v and %+v", err, r)
+	}
+
+	// a url on a saved host, given with -U
+	viper.Set("_url", "https://gitlab.example.com/gitlab/group/sub/repo")
+	defer viper.Set("_url", "")
+	r, err := loadFromConfigNoInit("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Host != "work" || r.Project_ != "group/sub/repo" || r.credentials != "hosts.work" || r.TokenSecret != "hosts.work.token" {
+		t.Errorf("expecting the repo group/sub/repo on host work, got %+v", r)
+	}
+}
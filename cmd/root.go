@@ -6,7 +6,9 @@ import (
 
 	"io/ioutil"
 	"log"
+	"time"
 
+	"github.com/clns/gitlab-cli/gitlab"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,8 +16,22 @@ import (
 var (
 	cfgFile              string
 	repo, repourl, token string
+	remoteName           string
 	user, password       string
+	apiVersion           string
 	verbose              bool
+	dryRun, assumeYes    bool
+	concurrency          int
+	retries              int
+	retryBackoff         time.Duration
+	insecure             bool
+	caFile               string
+	certFile, keyFile    string
+	proxy                string
+	noProxy              []string
+	headers              []string
+	secretStoreName      string
+	showSecrets          bool
 	configName           = ".gitlab-cli"
 )
 
@@ -53,13 +69,30 @@ func init() {
 	// Repository flags
 	RootCmd.PersistentFlags().StringVarP(&repo, "repo", "r", "", "repo name (as in the config file)")
 	RootCmd.PersistentFlags().StringVarP(&repourl, "url", "U", "", "repository URL, including the path (e.g. https://mygitlab.com/group/repo)")
+	RootCmd.PersistentFlags().StringVar(&remoteName, "remote", "", "git remote of the current directory to find the repo by, if no -r or -U (default origin)")
 	RootCmd.PersistentFlags().StringVarP(&token, "token", "t", "", "GitLab token (see http://doc.gitlab.com/ce/api/#authentication)")
 	RootCmd.PersistentFlags().StringVarP(&user, "user", "u", "", "GitLab login (user or email), if no token provided")
 	RootCmd.PersistentFlags().StringVarP(&password, "password", "p", "", "GitLab password, if no token provided (if empty, will prompt)")
+	RootCmd.PersistentFlags().StringVar(&apiVersion, "api-version", "", "GitLab API version, 'v3' or 'v4' (detected if empty)")
 	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "print logs")
+	RootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the changes instead of making them")
+	RootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "don't ask for confirmation before deleting")
+	RootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", gitlab.DefaultConcurrency, "number of requests to send at the same time when making many changes")
+	RootCmd.PersistentFlags().IntVar(&retries, "retries", gitlab.DefaultRetries, "times to retry a request that failed with a network error, 429 or 5xx (overrides the repo config)")
+	RootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", gitlab.DefaultRetryBackoff, "wait before the first retry, doubled for every other retry (overrides the repo config)")
+	RootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "don't verify the TLS certificate of the GitLab server (overrides the repo config)")
+	RootCmd.PersistentFlags().StringVar(&caFile, "ca-file", "", "PEM file with the CA certificates to trust (overrides the repo config)")
+	RootCmd.PersistentFlags().StringVar(&certFile, "cert-file", "", "PEM file with the TLS client certificate (overrides the repo config)")
+	RootCmd.PersistentFlags().StringVar(&keyFile, "key-file", "", "PEM file with the key of the TLS client certificate (overrides the repo config)")
+	RootCmd.PersistentFlags().StringVar(&proxy, "proxy", "", "proxy url, e.g. http://proxy:3128 (default from HTTP(S)_PROXY, overrides the repo config)")
+	RootCmd.PersistentFlags().StringSliceVar(&noProxy, "no-proxy", nil, "hosts, IPs or CIDR ranges to connect to without the proxy (overrides the repo config)")
+	RootCmd.PersistentFlags().StringArrayVar(&headers, "header", nil, "extra header to send as 'Name: value', can be repeated (overrides the repo config)")
+	RootCmd.PersistentFlags().StringVar(&secretStoreName, "secret-store", "", "where to save tokens: auto, keyring, file, encrypted-file or plain (default from secret_store in the config file, or auto)")
+	RootCmd.PersistentFlags().BoolVar(&showSecrets, "show-secrets", false, "print tokens and other secrets instead of masking them")
 
 	viper.BindPFlag("_url", RootCmd.PersistentFlags().Lookup("url"))
 	viper.BindPFlag("_token", RootCmd.PersistentFlags().Lookup("token"))
+	viper.BindPFlag("_api_version", RootCmd.PersistentFlags().Lookup("api-version"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -75,6 +108,6 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
@@ -0,0 +1,440 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/howeyc/gopass"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Secret stores, set with 'secret_store' in the config file.
+const (
+	storeAuto      = "auto"           // the keyring if available, the file otherwise
+	storeKeyring   = "keyring"        // the OS keyring (Secret Service or macOS Keychain)
+	storeFile      = "file"           // a file only the user can read
+	storeEncrypted = "encrypted-file" // a file encrypted with a passphrase
+	storePlain     = "plain"          // in the config file itself, as before
+)
+
+const (
+	keyringService    = "gitlab-cli"
+	secretsFileName   = ".gitlab-cli-secrets.json"
+	encryptedFileName = ".gitlab-cli-secrets.enc"
+	passphraseEnv     = "GITLAB_CLI_PASSPHRASE"
+)
+
+// readPassword reads a password from the terminal, replaceable in tests.
+var readPassword = gopass.GetPasswdMasked
+
+// secretStore stores secrets, like tokens, by key. The config file
+// only has the keys, so that it doesn't leak the secrets.
+type secretStore interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// getSecretStore returns the store given by --secret-store or 'secret_store'
+// in the config file, or nil for the plain store, that keeps the secrets in
+// the config.
+func getSecretStore() (secretStore, error) {
+	name := secretStoreName
+	if name == "" {
+		name = viper.GetString("secret_store")
+	}
+	if name == "" {
+		name = storeAuto
+	}
+	switch name {
+	case storeAuto:
+		if s := newKeyringStore(); s != nil {
+			return s, nil
+		}
+		return newFileStore(secretsFileName)
+	case storeKeyring:
+		if s := newKeyringStore(); s != nil {
+			return s, nil
+		}
+		return nil, fmt.Errorf("no OS keyring found (needs 'secret-tool' on Linux or 'security' on macOS)")
+	case storeFile:
+		return newFileStore(secretsFileName)
+	case storeEncrypted:
+		return newEncryptedFileStore(encryptedFileName)
+	case storePlain:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown secret_store '%s', must be one of: %s, %s, %s, %s, %s",
+		name, storeAuto, storeKeyring, storeFile, storeEncrypted, storePlain)
+}
+
+// keyringStore stores the secrets in the OS keyring, using its command
+// line tool: secret-tool (libsecret) on Linux or security on macOS.
+type keyringStore struct {
+	macOS bool
+}
+
+// newKeyringStore returns nil if there's no supported keyring.
+func newKeyringStore() *keyringStore {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return &keyringStore{macOS: true}
+		}
+	case "linux", "freebsd", "openbsd":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return &keyringStore{}
+		}
+	}
+	return nil
+}
+
+func (s *keyringStore) Get(key string) (string, error) {
+	var cmd *exec.Cmd
+	if s.macOS {
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", key, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "key", key)
+	}
+	out, err := s.run(cmd, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s' from the keyring: %v", key, err)
+	}
+	value := strings.TrimSuffix(out, "\n")
+	if value == "" {
+		return "", fmt.Errorf("'%s' is not in the keyring", key)
+	}
+	return value, nil
+}
+
+func (s *keyringStore) Set(key, value string) error {
+	var err error
+	if s.macOS {
+		// -w must be the last option to read the password from stdin
+		_, err = s.run(exec.Command("security", "add-generic-password", "-U",
+			"-s", keyringService, "-a", key, "-w"), value+"\n"+value+"\n")
+	} else {
+		_, err = s.run(exec.Command("secret-tool", "store", "--label", keyringService+": "+key,
+			"service", keyringService, "key", key), value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save '%s' in the keyring: %v", key, err)
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(key string) error {
+	var err error
+	if s.macOS {
+		_, err = s.run(exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", key), "")
+	} else {
+		_, err = s.run(exec.Command("secret-tool", "clear", "service", keyringService, "key", key), "")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete '%s' from the keyring: %v", key, err)
+	}
+	return nil
+}
+
+func (s *keyringStore) run(cmd *exec.Cmd, stdin string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// fileStore stores the secrets in a JSON file in the home directory,
+// that only the user can read. It is the fallback for systems without
+// a keyring, e.g. servers without D-Bus.
+type fileStore struct {
+	path string
+	// encrypt and decrypt the file contents, if not nil
+	encrypt, decrypt func([]byte) ([]byte, error)
+}
+
+func newFileStore(name string) (*fileStore, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{path: filepath.Join(home, name)}, nil
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("'%s' is not in %s", key, s.path)
+	}
+	return value, nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.write(secrets)
+}
+
+func (s *fileStore) Delete(key string) error {
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	delete(secrets, key)
+	return s.write(secrets)
+}
+
+func (s *fileStore) read() (map[string]string, error) {
+	secrets := make(map[string]string)
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.decrypt != nil {
+		if b, err = s.decrypt(b); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(b, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %v", s.path, err)
+	}
+	return secrets, nil
+}
+
+func (s *fileStore) write(secrets map[string]string) error {
+	b, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if s.encrypt != nil {
+		if b, err = s.encrypt(b); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(s.path, b, 0600)
+}
+
+// newEncryptedFileStore returns a fileStore that encrypts the file with
+// AES-GCM, with a key derived with scrypt from a passphrase, that is read
+// from $GITLAB_CLI_PASSPHRASE or asked for.
+func newEncryptedFileStore(name string) (*fileStore, error) {
+	s, err := newFileStore(name)
+	if err != nil {
+		return nil, err
+	}
+	var passphrase []byte
+	// getPassphrase asks for the passphrase twice if confirm is true,
+	// when the file is created, since a typo would make it unreadable.
+	getPassphrase := func(confirm bool) ([]byte, error) {
+		if passphrase != nil {
+			return passphrase, nil
+		}
+		if p := os.Getenv(passphraseEnv); p != "" {
+			passphrase = []byte(p)
+			return passphrase, nil
+		}
+		fmt.Fprint(os.Stderr, "Passphrase for the secrets file: ")
+		p, _ := readPassword()
+		if len(p) == 0 {
+			return nil, fmt.Errorf("empty passphrase for the secrets file")
+		}
+		if confirm {
+			fmt.Fprint(os.Stderr, "Repeat the passphrase: ")
+			if again, _ := readPassword(); !bytes.Equal(p, again) {
+				return nil, fmt.Errorf("the passphrases don't match")
+			}
+		}
+		passphrase = p
+		return passphrase, nil
+	}
+	s.encrypt = func(plain []byte) ([]byte, error) {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		_, err := os.Stat(s.path)
+		p, err := getPassphrase(os.IsNotExist(err))
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := newSecretsCipher(p, salt)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		return json.Marshal(&encryptedSecrets{
+			Version: 1,
+			Salt:    salt,
+			Nonce:   nonce,
+			Data:    gcm.Seal(nil, nonce, plain, nil),
+		})
+	}
+	s.decrypt = func(b []byte) ([]byte, error) {
+		var enc encryptedSecrets
+		if err := json.Unmarshal(b, &enc); err != nil || enc.Version != 1 {
+			return nil, fmt.Errorf("invalid encrypted secrets file %s", s.path)
+		}
+		p, err := getPassphrase(false)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := newSecretsCipher(p, enc.Salt)
+		if err != nil {
+			return nil, err
+		}
+		plain, err := gcm.Open(nil, enc.Nonce, enc.Data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s, wrong passphrase?", s.path)
+		}
+		return plain, nil
+	}
+	return s, nil
+}
+
+// encryptedSecrets is the format of the encrypted secrets file.
+type encryptedSecrets struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func newSecretsCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("empty passphrase for the secrets file")
+	}
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes the file by writing a temporary file
+// and renaming it, so that it is never left half written.
+func writeFileAtomic(path string, b []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// maskSecret hides a secret, keeping only a few characters
+// to tell it apart, unless --show-secrets is given.
+func maskSecret(s string) string {
+	if showSecrets || s == "" {
+		return s
+	}
+	if len(s) < 12 {
+		return "****"
+	}
+	return s[:4] + "****"
+}
+
+// maskURL hides the password in a url, like the proxy url,
+// unless --show-secrets is given.
+func maskURL(s string) string {
+	u, err := url.Parse(s)
+	if showSecrets || err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), "****")
+	return u.String()
+}
+
+// ownSecrets returns the keys of the secrets of the repo or host from
+// rep, that have the given config key, e.g. 'repos.myrepo'. Secrets
+// with other keys, e.g. set by hand, are left alone.
+func ownSecrets(rep *repoMap, key string) []string {
+	var keys []string
+	for _, k := range []string{rep.TokenSecret, rep.OAuthTokenSecret} {
+		if strings.HasPrefix(k, key+".") {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// deleteSecrets deletes the secrets with the given keys from the secret
+// store, after the config file no longer has them, warning on failure.
+func deleteSecrets(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	store, err := getSecretStore()
+	if err == nil && store == nil {
+		err = fmt.Errorf("secret_store is '%s'", storePlain)
+	}
+	for _, key := range keys {
+		if err == nil {
+			err = store.Delete(key)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to delete '%s' from the secret store: %v\n", key, err)
+		}
+	}
+}
+
+// moveSecret moves the secret with the old key to the new one.
+func moveSecret(store secretStore, oldKey, newKey string) error {
+	value, err := store.Get(oldKey)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(newKey, value); err != nil {
+		return err
+	}
+	return store.Delete(oldKey)
+}
@@ -142,9 +142,9 @@ func CheckUpdate() {
 func printUpdateAvl(latest string) {
 	if latest != Version {
 		if runtime.GOOS == "linux" {
-			fmt.Printf("New update available: %s. Run 'sudo gitlab-cli update' to update.\n", latest)
+			fmt.Fprintf(os.Stderr, "New update available: %s. Run 'sudo gitlab-cli update' to update.\n", latest)
 		} else {
-			fmt.Printf("New update available: %s. Run 'gitlab-cli update' to update.\n", latest)
+			fmt.Fprintf(os.Stderr, "New update available: %s. Run 'gitlab-cli update' to update.\n", latest)
 		}
 	}
 }
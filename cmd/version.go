@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 )
@@ -14,7 +15,9 @@ var versionCmd = &cobra.Command{
 	Aliases: []string{"v"},
 	Short:   "Print the version of this tool",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("gitlab-cli %v\n", Version)
+		mustRender(map[string]string{"version": Version}, func(w io.Writer) {
+			fmt.Fprintf(w, "gitlab-cli %v\n", Version)
+		})
 	},
 }
 
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +12,10 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// SaveViperConfig writes the config to the config file. The previous
+// file is kept as a backup, with the '.bak' extension, and the new one
+// is written to a temporary file first, so that it's never left half
+// written if writing fails.
 func SaveViperConfig() error {
 	filename := viper.ConfigFileUsed()
 	if filename == "" {
@@ -20,15 +25,11 @@ func SaveViperConfig() error {
 		}
 		filename = filepath.Join(hdir, configName+".yml")
 	}
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
 
 	all := viper.AllSettings()
-	for k, _ := range all {
-		if strings.HasPrefix(k, "_") {
+	for k, v := range all {
+		// flags, and settings that were unset, like the default repo
+		if strings.HasPrefix(k, "_") || v == "" {
 			delete(all, k)
 		}
 	}
@@ -36,8 +37,16 @@ func SaveViperConfig() error {
 	if err != nil {
 		return fmt.Errorf("Panic while encoding into YAML format.")
 	}
-	if _, err := f.WriteString(string(b)); err != nil {
-		return err
+
+	// the config file can have tokens
+	perm := os.FileMode(0600)
+	if old, err := ioutil.ReadFile(filename); err == nil {
+		if fi, err := os.Stat(filename); err == nil {
+			perm = fi.Mode().Perm()
+		}
+		if err := writeFileAtomic(filename+".bak", old, perm); err != nil {
+			return fmt.Errorf("failed to back up the config file: %v", err)
+		}
 	}
-	return nil
+	return writeFileAtomic(filename, b, perm)
 }
@@ -0,0 +1,63 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNewClient_DetectsAPIVersion(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	u, _ := url.Parse(f.URL)
+
+	c, err := NewClient(u, f.Token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.APIVersion != APIv4 {
+		t.Errorf("expecting API %s, got %s", APIv4, c.APIVersion)
+	}
+
+	f.Versions = []string{APIv3}
+	if c, err = NewClient(u, f.Token, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c.APIVersion != APIv3 {
+		t.Errorf("expecting API %s, got %s", APIv3, c.APIVersion)
+	}
+
+	if _, err = NewClient(u, f.Token, &Options{APIVersion: "v2"}); err == nil {
+		t.Errorf("expecting error for unsupported API version")
+	}
+
+	// a failure is not taken for either version
+	f.Versions = []string{APIv3, APIv4}
+	f.FailOn("GET", "^version$", http.StatusBadGateway, 1)
+	if c, err = NewClient(u, f.Token, nil); err == nil {
+		t.Errorf("expecting error when the server fails, got API %s", c.APIVersion)
+	}
+	if c, err = NewClient(u, "invalid", nil); err != nil || c.APIVersion != APIv4 {
+		t.Errorf("expecting API %s with an invalid token, got %v, %v", APIv4, c, err)
+	}
+}
+
+func TestNewClientForUser(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	u, _ := url.Parse(f.URL)
+
+	for _, version := range []string{APIv3, APIv4} {
+		c, err := NewClientForUser(u, f.User, f.Password, &Options{APIVersion: version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Token != f.Token || c.APIVersion != version {
+			t.Errorf("expecting token '%s' on %s, got '%s' on %s", f.Token, version, c.Token, c.APIVersion)
+		}
+	}
+
+	if _, err := NewClientForUser(u, f.User, "wrong", nil); err == nil {
+		t.Errorf("expecting error for wrong password")
+	}
+}
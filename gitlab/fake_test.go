@@ -0,0 +1,1378 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+// fakeGitLab is an in-memory GitLab server that implements the subset
+// of the API used by this package, so tests can run without a real
+// GitLab instance.
+type fakeGitLab struct {
+	*httptest.Server
+
+	// Token is the private token every request must carry.
+	Token string
+	// User and Password are accepted by the session endpoint.
+	User, Password string
+	// Versions are the API versions the server responds to.
+	Versions []string
+	// ServerVersion is the GitLab version of the server.
+	ServerVersion string
+	// PerPage is the default page size for paginated lists on v4.
+	PerPage int
+	// Delay is added before every response, to simulate a slow server.
+	Delay time.Duration
+	// IgnoreLabelChanges makes the server ignore add_labels and
+	// remove_labels, like GitLab versions that don't have them.
+	IgnoreLabelChanges bool
+	// IgnoreAncestorGroups makes the server list the labels of the
+	// ancestor groups with the ones of a project or group, even with
+	// include_ancestor_groups=false, like GitLab versions before 13.6.
+	IgnoreAncestorGroups bool
+	// TokenExpiresAt is the expiry date of the private Token, if any.
+	TokenExpiresAt string
+	// OAuth enables the OAuth2 endpoints, for the application with
+	// ClientID, that issue tokens valid for TokenTTL (forever if 0).
+	OAuth    bool
+	ClientID string
+	TokenTTL time.Duration
+
+	mu           sync.Mutex
+	nextID       int
+	projects     map[int]*fakeProject
+	groups       map[string]*fakeGroup
+	globalLabels []*gogitlab.Label
+	failures     []*fakeFailure
+	requests     []string
+	// accessTokens are the issued OAuth2 tokens, refreshTokens and codes
+	// (authorization and device) map to the scope.
+	accessTokens  map[string]*fakeToken
+	refreshTokens map[string]string
+	codes         map[string]string
+}
+
+type fakeProject struct {
+	project    *gogitlab.Project
+	labels     []*gogitlab.Label
+	issues     []*gogitlab.Issue
+	mrs        []*MergeRequest
+	milestones []*gogitlab.Milestone
+	// approved are the IDs of the merge requests the user approved.
+	approved map[int]bool
+	// diffs are the diffs of the merge requests set with SetDiff, by ID.
+	diffs map[int]string
+}
+
+type fakeGroup struct {
+	group  *gogitlab.Group
+	labels []*gogitlab.Label
+}
+
+type fakeToken struct {
+	scope   string
+	expires time.Time
+}
+
+type fakeFailure struct {
+	method string
+	path   *regexp.Regexp
+	status int
+	times  int
+}
+
+// newFakeGitLab starts a fake GitLab server.
+// The caller should Close() it when done.
+func newFakeGitLab() *fakeGitLab {
+	f := &fakeGitLab{
+		Token:         "secret",
+		User:          "root",
+		Password:      "password",
+		Versions:      []string{APIv3, APIv4},
+		ServerVersion: "9.5.0",
+		PerPage:       20,
+		nextID:        1,
+		projects:      make(map[int]*fakeProject),
+		groups:        make(map[string]*fakeGroup),
+		ClientID:      "app",
+
+		accessTokens:  make(map[string]*fakeToken),
+		refreshTokens: make(map[string]string),
+		codes:         make(map[string]string),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// Client returns a new Client for the fake server,
+// using the given API version.
+func (f *fakeGitLab) Client(tb testing.TB, version string) *Client {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	c, err := NewClient(u, f.Token, &Options{APIVersion: version})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return c
+}
+
+// AddGlobalLabel adds a label template, that gets copied
+// into every project created afterwards.
+func (f *fakeGitLab) AddGlobalLabel(name, color, description string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.globalLabels = append(f.globalLabels, &gogitlab.Label{
+		Name:        name,
+		Color:       color,
+		Description: description,
+	})
+}
+
+// AddProject adds a project with the given path, that can
+// include nested groups (e.g. 'group/subgroup/repo').
+func (f *fakeGitLab) AddProject(path string) *gogitlab.Project {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addProject(path, "")
+}
+
+// AddGroup adds a group with the given path, that has labels
+// on v4. Groups don't need to be added to list their projects.
+func (f *fakeGitLab) AddGroup(path string) *gogitlab.Group {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g := &gogitlab.Group{ID: f.nextID, Name: path[strings.LastIndex(path, "/")+1:], Path: path}
+	f.nextID++
+	f.groups[path] = &fakeGroup{group: g}
+	return g
+}
+
+// GroupLabels returns the labels of a group added with AddGroup.
+func (f *fakeGitLab) GroupLabels(path string) []*gogitlab.Label {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	labels := append([]*gogitlab.Label(nil), f.groups[path].labels...)
+	sort.Sort(labelsByName(labels))
+	return labels
+}
+
+// AddIssue adds an open issue with the given title and labels
+// to a project added with AddProject.
+func (f *fakeGitLab) AddIssue(pid int, title string, labels ...string) *gogitlab.Issue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.projects[pid]
+	now := time.Now()
+	issue := &gogitlab.Issue{
+		ID:        f.nextID,
+		IID:       len(p.issues) + 1,
+		ProjectID: pid,
+		Title:     title,
+		Labels:    append([]string{}, labels...),
+		State:     "opened",
+		Author:    &gogitlab.IssueUser{ID: 1, Username: f.User},
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}
+	f.nextID++
+	p.issues = append(p.issues, issue)
+	i := *issue
+	return &i
+}
+
+// AddMergeRequest adds an open merge request with the given title
+// and labels to a project added with AddProject, from the branch
+// feature-<IID> to master.
+func (f *fakeGitLab) AddMergeRequest(pid int, title string, labels ...string) *MergeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.projects[pid]
+	mr := f.newMergeRequest(p, fmt.Sprintf("feature-%d", len(p.mrs)+1), "master")
+	mr.Title, mr.Labels = title, append([]string{}, labels...)
+	p.mrs = append(p.mrs, mr)
+	m := *mr
+	return &m
+}
+
+// SetPipeline sets the status of the pipeline of a merge request added
+// with AddMergeRequest, e.g. "running" or "success".
+func (f *fakeGitLab) SetPipeline(pid, iid int, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	mr := f.projects[pid].mrs[iid-1]
+	mr.Pipeline = &Pipeline{ID: f.nextID, Status: status, Ref: mr.SourceBranch, SHA: mr.SHA}
+	f.nextID++
+	if status == "success" && mr.MergeWhenPipelineSucceeds {
+		mr.State, mr.MergeWhenPipelineSucceeds = "merged", false
+	}
+}
+
+// SetDiff sets the diff of a merge request added with AddMergeRequest,
+// instead of fakeDiff.
+func (f *fakeGitLab) SetDiff(pid, iid int, diff string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.projects[pid]
+	p.diffs[p.mrs[iid-1].ID] = diff
+}
+
+// Approved returns true if the user approved a merge request.
+func (f *fakeGitLab) Approved(pid, iid int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.projects[pid]
+	return p.approved[p.mrs[iid-1].ID]
+}
+
+// Issue returns an issue added to a project, by IID.
+func (f *fakeGitLab) Issue(pid, iid int) *gogitlab.Issue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i := *f.projects[pid].issues[iid-1]
+	return &i
+}
+
+// SetIssueLabels sets the labels of an issue added to a project, by IID.
+func (f *fakeGitLab) SetIssueLabels(pid, iid int, labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.projects[pid].issues[iid-1].Labels = labels
+}
+
+// ClearIssueDates removes the creation and update dates of an issue
+// added to a project, by IID, like issues imported without them.
+func (f *fakeGitLab) ClearIssueDates(pid, iid int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	issue := f.projects[pid].issues[iid-1]
+	issue.CreatedAt, issue.UpdatedAt = nil, nil
+}
+
+// MergeRequest returns a merge request added to a project, by IID.
+func (f *fakeGitLab) MergeRequest(pid, iid int) *MergeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := *f.projects[pid].mrs[iid-1]
+	return &m
+}
+
+// AddMilestone adds a milestone to a project added with AddProject.
+func (f *fakeGitLab) AddMilestone(pid int, title string) *gogitlab.Milestone {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := f.projects[pid]
+	m := &gogitlab.Milestone{ID: f.nextID, IID: len(p.milestones) + 1, ProjectID: pid, Title: title, State: "active"}
+	f.nextID++
+	p.milestones = append(p.milestones, m)
+	return m
+}
+
+// FailOn makes the next times requests (or all of them if times is 0)
+// that match method and the path pattern fail with the given status.
+// Requests failed with 429 Too Many Requests get a Retry-After of 1s.
+// The path is relative to the API root, e.g. "projects/1/labels".
+func (f *fakeGitLab) FailOn(method, pattern string, status, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, &fakeFailure{
+		method: method,
+		path:   regexp.MustCompile(pattern),
+		status: status,
+		times:  times,
+	})
+}
+
+// Requests returns the requests made so far, as "METHOD path".
+func (f *fakeGitLab) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *fakeGitLab) addProject(path, desc string) *gogitlab.Project {
+	namespace, name := "", path
+	if i := strings.LastIndex(path, "/"); i != -1 {
+		namespace, name = path[:i], path[i+1:]
+	}
+	proj := &gogitlab.Project{
+		ID:                f.nextID,
+		Name:              name,
+		Path:              name,
+		PathWithNamespace: path,
+		NameWithNamespace: strings.Replace(path, "/", " / ", -1),
+		Description:       desc,
+		WebURL:            f.URL + "/" + path,
+		DefaultBranch:     "master",
+		Namespace:         &gogitlab.ProjectNamespace{Name: namespace, Path: namespace},
+	}
+	f.nextID++
+	p := &fakeProject{project: proj, approved: map[int]bool{}, diffs: map[int]string{}}
+	for _, l := range f.globalLabels {
+		label := *l
+		p.labels = append(p.labels, &label)
+	}
+	f.projects[proj.ID] = p
+	return proj
+}
+
+func (f *fakeGitLab) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.Delay > 0 {
+		time.Sleep(f.Delay)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/oauth/") && f.OAuth {
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		f.serveOAuth(w, r)
+		return
+	}
+
+	version, path, ok := f.splitPath(r.URL.EscapedPath())
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "404 Not Found"})
+		return
+	}
+	f.requests = append(f.requests, r.Method+" "+path)
+
+	for i, fail := range f.failures {
+		if fail.method == r.Method && fail.path.MatchString(path) {
+			if fail.times > 0 {
+				if fail.times--; fail.times == 0 {
+					f.failures = append(f.failures[:i], f.failures[i+1:]...)
+				}
+			}
+			if fail.status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+			writeJSON(w, fail.status, map[string]string{"message": http.StatusText(fail.status)})
+			return
+		}
+	}
+
+	if path == "session" && r.Method == "POST" {
+		f.serveSession(w, r)
+		return
+	}
+	if r.Header.Get("PRIVATE-TOKEN") != f.Token && !f.validAccessToken(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "401 Unauthorized"})
+		return
+	}
+
+	seg := strings.Split(path, "/")
+	for i := range seg {
+		seg[i], _ = url.QueryUnescape(seg[i])
+	}
+	switch {
+	case path == "version":
+		writeJSON(w, http.StatusOK, map[string]string{"version": f.ServerVersion})
+	case path == "personal_access_tokens/self" && version == APIv4 && r.Header.Get("PRIVATE-TOKEN") == f.Token:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"name": "test", "scopes": []string{"api"}, "expires_at": f.TokenExpiresAt})
+	case path == "user":
+		writeJSON(w, http.StatusOK, f.user())
+	case path == "users":
+		users := []*gogitlab.User{}
+		if name := r.URL.Query().Get("username"); name == "" || name == f.User {
+			users = append(users, f.user())
+		}
+		writeJSON(w, http.StatusOK, users)
+	case (seg[0] == "groups" || seg[0] == "users") && len(seg) == 3 && seg[2] == "projects":
+		f.serveNamespaceProjects(w, r, seg[0], seg[1])
+	case seg[0] == "groups" && f.groups[seg[1]] != nil && len(seg) == 2:
+		writeJSON(w, http.StatusOK, f.groups[seg[1]].group)
+	case seg[0] == "groups" && f.groups[seg[1]] != nil && len(seg) == 3 && seg[2] == "labels" && version == APIv4:
+		f.serveLabels(w, r, version, nil, f.groups[seg[1]])
+	case seg[0] == "projects" && len(seg) == 1:
+		f.serveProjects(w, r)
+	case seg[0] == "projects" && len(seg) == 3 && seg[1] == "search":
+		f.serveSearch(w, r, seg[2])
+	case seg[0] == "projects":
+		p := f.findProject(seg[1])
+		if p == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Project Not Found"})
+			return
+		}
+		switch {
+		case len(seg) == 2:
+			f.serveProject(w, r, p)
+		case len(seg) == 3 && seg[2] == "labels":
+			f.serveLabels(w, r, version, p, nil)
+		case len(seg) == 5 && seg[2] == "labels" && seg[4] == "promote" && version == APIv4 && r.Method == "PUT":
+			f.servePromote(w, p, seg[3])
+		case len(seg) == 3 && seg[2] == "issues":
+			f.serveIssues(w, r, p)
+		case len(seg) == 4 && seg[2] == "issues":
+			f.serveIssue(w, r, version, p, seg[3])
+		case len(seg) == 3 && seg[2] == "merge_requests":
+			f.serveMergeRequests(w, r, p)
+		case len(seg) == 4 && seg[2] == "merge_requests":
+			f.serveMergeRequest(w, r, version, p, seg[3], "")
+		case len(seg) == 5 && seg[2] == "merge_requests":
+			f.serveMergeRequest(w, r, version, p, seg[3], seg[4])
+		case len(seg) == 3 && seg[2] == "milestones" && r.Method == "GET":
+			writeJSON(w, http.StatusOK, p.milestones)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "404 Not Found"})
+		}
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "404 Not Found"})
+	}
+}
+
+// splitPath returns the API version and the path relative to the API root.
+func (f *fakeGitLab) splitPath(p string) (version, path string, ok bool) {
+	for _, v := range f.Versions {
+		if prefix := apiPath(v); strings.HasPrefix(p, prefix) {
+			return v, strings.TrimSuffix(p[len(prefix):], "/"), true
+		}
+	}
+	return "", "", false
+}
+
+func (f *fakeGitLab) findProject(id string) *fakeProject {
+	if n, err := strconv.Atoi(id); err == nil {
+		return f.projects[n]
+	}
+	for _, p := range f.projects {
+		if p.project.PathWithNamespace == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (f *fakeGitLab) serveSession(w http.ResponseWriter, r *http.Request) {
+	var opt gogitlab.GetSessionOptions
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	if opt.Login == nil || *opt.Login != f.User || opt.Password == nil || *opt.Password != f.Password {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "401 Unauthorized"})
+		return
+	}
+	writeJSON(w, http.StatusCreated, &gogitlab.Session{Username: f.User, PrivateToken: f.Token})
+}
+
+// validAccessToken returns true if the request has an OAuth2 access
+// token that didn't expire.
+func (f *fakeGitLab) validAccessToken(r *http.Request) bool {
+	return f.accessToken(r) != nil
+}
+
+// accessToken returns the OAuth2 access token of the request,
+// if it didn't expire.
+func (f *fakeGitLab) accessToken(r *http.Request) *fakeToken {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return nil
+	}
+	tok := f.accessTokens[strings.TrimPrefix(auth, "Bearer ")]
+	if tok == nil || (!tok.expires.IsZero() && !time.Now().Before(tok.expires)) {
+		return nil
+	}
+	return tok
+}
+
+// serveOAuth serves the OAuth2 endpoints: the token endpoint with the
+// password, refresh token, authorization code and device code grants,
+// the authorization endpoint, that authorizes right away, and the device
+// authorization endpoint, whose codes are authorized on the second poll.
+func (f *fakeGitLab) serveOAuth(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	oauthError := func(status int, code string) {
+		writeJSON(w, status, map[string]string{"error": code, "error_description": code})
+	}
+	if clientID := r.Form.Get("client_id"); clientID != "" && clientID != f.ClientID {
+		oauthError(http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	switch r.URL.Path {
+	case "/oauth/token/info":
+		tok := f.accessToken(r)
+		if tok == nil {
+			oauthError(http.StatusUnauthorized, "invalid_token")
+			return
+		}
+		info := map[string]interface{}{"scope": strings.Fields(tok.scope), "expires_in_seconds": nil}
+		if !tok.expires.IsZero() {
+			info["expires_in_seconds"] = int64(tok.expires.Sub(time.Now()) / time.Second)
+		}
+		writeJSON(w, http.StatusOK, info)
+	case "/oauth/authorize":
+		code := RandomString(10)
+		f.codes[code] = r.Form.Get("scope")
+		u, _ := url.Parse(r.Form.Get("redirect_uri"))
+		u.RawQuery = url.Values{"code": {code}, "state": {r.Form.Get("state")}}.Encode()
+		http.Redirect(w, r, u.String(), http.StatusFound)
+	case "/oauth/authorize_device":
+		code := RandomString(10)
+		f.codes[code] = "pending " + r.Form.Get("scope")
+		writeJSON(w, http.StatusOK, &DeviceCode{
+			UserCode:        strings.ToUpper(code[:6]),
+			VerificationURI: f.URL + "/oauth/device",
+			ExpiresIn:       300,
+			Interval:        1,
+			DeviceCode:      code,
+		})
+	case "/oauth/token":
+		var scope string
+		switch r.Form.Get("grant_type") {
+		case "password":
+			if r.Form.Get("username") != f.User || r.Form.Get("password") != f.Password {
+				oauthError(http.StatusBadRequest, "invalid_grant")
+				return
+			}
+			scope = r.Form.Get("scope")
+		case "refresh_token":
+			var ok bool
+			if scope, ok = f.refreshTokens[r.Form.Get("refresh_token")]; !ok {
+				oauthError(http.StatusBadRequest, "invalid_grant")
+				return
+			}
+			delete(f.refreshTokens, r.Form.Get("refresh_token"))
+		case "authorization_code", "urn:ietf:params:oauth:grant-type:device_code":
+			code := r.Form.Get("code") + r.Form.Get("device_code")
+			var ok bool
+			if scope, ok = f.codes[code]; !ok {
+				oauthError(http.StatusBadRequest, "invalid_grant")
+				return
+			}
+			if strings.HasPrefix(scope, "pending ") {
+				f.codes[code] = strings.TrimPrefix(scope, "pending ")
+				oauthError(http.StatusBadRequest, "authorization_pending")
+				return
+			}
+			delete(f.codes, code)
+		default:
+			oauthError(http.StatusBadRequest, "unsupported_grant_type")
+			return
+		}
+		access, refresh := RandomString(20), RandomString(20)
+		var expires time.Time
+		if f.TokenTTL > 0 {
+			expires = time.Now().Add(f.TokenTTL)
+		}
+		f.accessTokens[access] = &fakeToken{scope: scope, expires: expires}
+		f.refreshTokens[refresh] = scope
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token":  access,
+			"token_type":    "Bearer",
+			"refresh_token": refresh,
+			"scope":         scope,
+			"expires_in":    int64(f.TokenTTL / time.Second),
+			"created_at":    time.Now().Unix(),
+		})
+	default:
+		oauthError(http.StatusNotFound, "not_found")
+	}
+}
+
+// ExpireAccessTokens makes all the OAuth2 access tokens issued so far expire.
+func (f *fakeGitLab) ExpireAccessTokens() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.accessTokens {
+		token.expires = time.Now().Add(-time.Second)
+	}
+}
+
+func (f *fakeGitLab) serveProjects(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, f.sortedProjects())
+	case "POST":
+		var opt gogitlab.CreateProjectOptions
+		if err := json.NewDecoder(r.Body).Decode(&opt); err != nil || opt.Name == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "name is missing"})
+			return
+		}
+		path := "root/" + strings.ToLower(*opt.Name)
+		if f.findProject(path) != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "has already been taken"})
+			return
+		}
+		var desc string
+		if opt.Description != nil {
+			desc = *opt.Description
+		}
+		writeJSON(w, http.StatusCreated, f.addProject(path, desc))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// ancestorGroupLabels returns the labels of the groups added with
+// AddGroup that are the namespace or its ancestors.
+func (f *fakeGitLab) ancestorGroupLabels(namespace string) []*gogitlab.Label {
+	var labels []*gogitlab.Label
+	for ; namespace != "." && namespace != "/" && namespace != ""; namespace = path.Dir(namespace) {
+		if g := f.groups[namespace]; g != nil {
+			sorted := append([]*gogitlab.Label(nil), g.labels...)
+			sort.Sort(labelsByName(sorted))
+			labels = append(labels, sorted...)
+		}
+	}
+	return labels
+}
+
+// groupProjects returns the projects of a group and its subgroups.
+func (f *fakeGitLab) groupProjects(group string) []*fakeProject {
+	var projects []*fakeProject
+	for _, p := range f.projects {
+		if ns := p.project.Namespace.Path; ns == group || strings.HasPrefix(ns, group+"/") {
+			projects = append(projects, p)
+		}
+	}
+	return projects
+}
+
+// serveNamespaceProjects serves the projects of a group (including
+// subgroups, if asked for) or of a user (a top level namespace).
+func (f *fakeGitLab) serveNamespaceProjects(w http.ResponseWriter, r *http.Request, kind, namespace string) {
+	subgroups := kind == "groups" && r.URL.Query().Get("include_subgroups") == "true"
+	var found []*gogitlab.Project
+	for _, p := range f.sortedProjects() {
+		ns := p.Namespace.Path
+		if kind == "users" && strings.Contains(ns, "/") {
+			continue
+		}
+		if ns == namespace || (subgroups && strings.HasPrefix(ns, namespace+"/")) {
+			found = append(found, p)
+		}
+	}
+	if found == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Group Not Found"})
+		return
+	}
+	writePage(w, r, f.PerPage, len(found), func(from, to int) interface{} {
+		return found[from:to]
+	})
+}
+
+func (f *fakeGitLab) serveSearch(w http.ResponseWriter, r *http.Request, query string) {
+	var found []*gogitlab.Project
+	for _, p := range f.sortedProjects() {
+		if strings.Contains(p.Name, query) {
+			found = append(found, p)
+		}
+	}
+	writeJSON(w, http.StatusOK, found)
+}
+
+func (f *fakeGitLab) serveProject(w http.ResponseWriter, r *http.Request, p *fakeProject) {
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, p.project)
+	case "DELETE":
+		delete(f.projects, p.project.ID)
+		writeJSON(w, http.StatusOK, p.project)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeGitLab) user() *gogitlab.User {
+	return &gogitlab.User{ID: 1, Username: f.User, Name: "Administrator", State: "active", IsAdmin: true}
+}
+
+// fakeIssueRequest is the body of the requests that create
+// or update issues.
+type fakeIssueRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Labels      *string `json:"labels"`
+	// AddLabels and RemoveLabels change the labels on v4.
+	AddLabels    *string `json:"add_labels"`
+	RemoveLabels *string `json:"remove_labels"`
+	MilestoneID  *int    `json:"milestone_id"`
+	AssigneeID   *int    `json:"assignee_id"`
+	StateEvent   *string `json:"state_event"`
+}
+
+// serveIssues lists, newest first, and creates the issues of a project.
+func (f *fakeGitLab) serveIssues(w http.ResponseWriter, r *http.Request, p *fakeProject) {
+	switch r.Method {
+	case "GET":
+		q := r.URL.Query()
+		issues := []*gogitlab.Issue{}
+		for i := len(p.issues) - 1; i >= 0; i-- {
+			issue := p.issues[i]
+			if fakeIssueMatches(issue, q) {
+				issues = append(issues, issue)
+			}
+		}
+		writePage(w, r, f.PerPage, len(issues), func(from, to int) interface{} {
+			return issues[from:to]
+		})
+	case "POST":
+		var req fakeIssueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		if req.Title == nil || *req.Title == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title is missing"})
+			return
+		}
+		now := time.Now()
+		issue := &gogitlab.Issue{
+			ID:        f.nextID,
+			IID:       len(p.issues) + 1,
+			ProjectID: p.project.ID,
+			Labels:    []string{},
+			State:     "opened",
+			Author:    &gogitlab.IssueUser{ID: 1, Username: f.User},
+			CreatedAt: &now,
+		}
+		f.nextID++
+		if status, msg := f.updateIssue(p, issue, &req); status != http.StatusOK {
+			writeJSON(w, status, map[string]string{"message": msg})
+			return
+		}
+		p.issues = append(p.issues, issue)
+		writeJSON(w, http.StatusCreated, issue)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveIssue gets and updates an issue, by IID on v4 and by ID on v3.
+func (f *fakeGitLab) serveIssue(w http.ResponseWriter, r *http.Request, version string, p *fakeProject, id string) {
+	var issue *gogitlab.Issue
+	for _, i := range p.issues {
+		if (version == APIv4 && strconv.Itoa(i.IID) == id) || (version == APIv3 && strconv.Itoa(i.ID) == id) {
+			issue = i
+		}
+	}
+	if issue == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, issue)
+	case "PUT":
+		var req fakeIssueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		updated := *issue
+		if status, msg := f.updateIssue(p, &updated, &req); status != http.StatusOK {
+			writeJSON(w, status, map[string]string{"message": msg})
+			return
+		}
+		*issue = updated
+		writeJSON(w, http.StatusOK, issue)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// updateIssue sets the fields of the request on the issue. It returns
+// the status and error message of the response if the request is invalid.
+func (f *fakeGitLab) updateIssue(p *fakeProject, issue *gogitlab.Issue, req *fakeIssueRequest) (int, string) {
+	if req.Title != nil {
+		if *req.Title == "" {
+			return http.StatusBadRequest, "title is empty"
+		}
+		issue.Title = *req.Title
+	}
+	if req.Description != nil {
+		issue.Description = *req.Description
+	}
+	if req.Labels != nil {
+		issue.Labels = splitLabels(*req.Labels)
+	}
+	if req.AddLabels != nil && !f.IgnoreLabelChanges {
+		for _, l := range splitLabels(*req.AddLabels) {
+			if !hasLabel(issue.Labels, l) {
+				issue.Labels = append(issue.Labels, l)
+			}
+		}
+	}
+	if req.RemoveLabels != nil && !f.IgnoreLabelChanges {
+		remove := splitLabels(*req.RemoveLabels)
+		labels := []string{}
+		for _, l := range issue.Labels {
+			if !hasLabel(remove, l) {
+				labels = append(labels, l)
+			}
+		}
+		issue.Labels = labels
+	}
+	if req.MilestoneID != nil {
+		issue.Milestone = nil
+		for _, m := range p.milestones {
+			if m.ID == *req.MilestoneID {
+				issue.Milestone = m
+			}
+		}
+		if issue.Milestone == nil && *req.MilestoneID != 0 {
+			return http.StatusNotFound, "404 Milestone Not Found"
+		}
+	}
+	if req.AssigneeID != nil {
+		issue.Assignee = nil
+		if *req.AssigneeID == 1 {
+			issue.Assignee = &gogitlab.IssueUser{ID: 1, Username: f.User}
+		} else if *req.AssigneeID != 0 {
+			return http.StatusNotFound, "404 User Not Found"
+		}
+	}
+	if req.StateEvent != nil {
+		switch *req.StateEvent {
+		case "close":
+			issue.State = "closed"
+		case "reopen":
+			issue.State = "reopened"
+		default:
+			return http.StatusBadRequest, "state_event does not have a valid value"
+		}
+	}
+	now := time.Now()
+	issue.UpdatedAt = &now
+	return http.StatusOK, ""
+}
+
+// fakeMergeRequestRequest is the body of the requests that create
+// or update merge requests.
+type fakeMergeRequestRequest struct {
+	fakeIssueRequest
+	SourceBranch       *string `json:"source_branch"`
+	TargetBranch       *string `json:"target_branch"`
+	RemoveSourceBranch *bool   `json:"remove_source_branch"`
+	Squash             *bool   `json:"squash"`
+}
+
+// fakeMergeRequest is the body of the requests that merge a merge request.
+type fakeMergeRequest struct {
+	ShouldRemoveSourceBranch  bool   `json:"should_remove_source_branch"`
+	MergeWhenPipelineSucceeds bool   `json:"merge_when_pipeline_succeeds"`
+	MergeWhenBuildSucceeds    bool   `json:"merge_when_build_succeeds"` // v3
+	Squash                    bool   `json:"squash"`
+	SHA                       string `json:"sha"`
+}
+
+// fakeDiff is the diff of the merge requests, unless set with SetDiff.
+const fakeDiff = "@@ -1 +1,2 @@\n-# Project\n+# My project\n+\n"
+
+// newMergeRequest returns an open merge request in a project, without
+// adding it, with the next ID.
+func (f *fakeGitLab) newMergeRequest(p *fakeProject, source, target string) *MergeRequest {
+	now := time.Now()
+	mr := &MergeRequest{}
+	mr.ID = f.nextID
+	mr.IID = len(p.mrs) + 1
+	mr.ProjectID = p.project.ID
+	mr.Labels = []string{}
+	mr.State = "opened"
+	mr.SourceBranch, mr.TargetBranch = source, target
+	mr.SHA = fmt.Sprintf("%040x", f.nextID)
+	mr.MergeStatus = "can_be_merged"
+	mr.Author = &gogitlab.IssueUser{ID: 1, Username: f.User}
+	mr.WebURL = fmt.Sprintf("%s/merge_requests/%d", p.project.WebURL, mr.IID)
+	mr.CreatedAt, mr.UpdatedAt = &now, &now
+	f.nextID++
+	return mr
+}
+
+// serveMergeRequests lists, newest first, and creates
+// the merge requests of a project.
+func (f *fakeGitLab) serveMergeRequests(w http.ResponseWriter, r *http.Request, p *fakeProject) {
+	switch r.Method {
+	case "GET":
+		q := r.URL.Query()
+		state := q.Get("state")
+		q.Del("state")
+		mrs := []*MergeRequest{}
+		for i := len(p.mrs) - 1; i >= 0; i-- {
+			mr := p.mrs[i]
+			if state != "" && state != "all" && state != mr.State && !(state == "opened" && mr.State == "reopened") {
+				continue
+			}
+			if b := q.Get("source_branch"); b != "" && b != mr.SourceBranch {
+				continue
+			}
+			if b := q.Get("target_branch"); b != "" && b != mr.TargetBranch {
+				continue
+			}
+			if !fakeIssueMatches(fakeMergeRequestIssue(mr), q) {
+				continue
+			}
+			mrs = append(mrs, mr)
+		}
+		writePage(w, r, f.PerPage, len(mrs), func(from, to int) interface{} {
+			return mrs[from:to]
+		})
+	case "POST":
+		var req fakeMergeRequestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		if req.Title == nil || *req.Title == "" || req.SourceBranch == nil || req.TargetBranch == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title, source_branch or target_branch is missing"})
+			return
+		}
+		for _, mr := range p.mrs {
+			if mr.State == "opened" && mr.SourceBranch == *req.SourceBranch && mr.TargetBranch == *req.TargetBranch {
+				writeJSON(w, http.StatusConflict, map[string][]string{"message": {"Another open merge request already exists for this source branch: !" + strconv.Itoa(mr.IID)}})
+				return
+			}
+		}
+		mr := f.newMergeRequest(p, *req.SourceBranch, *req.TargetBranch)
+		if status, msg := f.updateMergeRequest(p, mr, &req); status != http.StatusOK {
+			writeJSON(w, status, map[string]string{"message": msg})
+			return
+		}
+		p.mrs = append(p.mrs, mr)
+		writeJSON(w, http.StatusCreated, mr)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveMergeRequest gets and updates a merge request, by IID on v4 and
+// by ID on v3, or makes the action on it: merge, changes, approve or
+// unapprove.
+func (f *fakeGitLab) serveMergeRequest(w http.ResponseWriter, r *http.Request, version string, p *fakeProject, id, action string) {
+	var mr *MergeRequest
+	for _, m := range p.mrs {
+		if (version == APIv4 && strconv.Itoa(m.IID) == id) || (version == APIv3 && strconv.Itoa(m.ID) == id) {
+			mr = m
+		}
+	}
+	if mr == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+		return
+	}
+	switch {
+	case action == "" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, mr)
+	case action == "" && r.Method == "PUT":
+		var req fakeMergeRequestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		updated := *mr
+		if status, msg := f.updateMergeRequest(p, &updated, &req); status != http.StatusOK {
+			writeJSON(w, status, map[string]string{"message": msg})
+			return
+		}
+		*mr = updated
+		writeJSON(w, http.StatusOK, mr)
+	case action == "changes" && r.Method == "GET":
+		diff, ok := p.diffs[mr.ID]
+		if !ok {
+			diff = fakeDiff
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"iid":     mr.IID,
+			"changes": []map[string]string{{"old_path": "README.md", "new_path": "README.md", "diff": diff}},
+		})
+	case action == "merge" && r.Method == "PUT":
+		var req fakeMergeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		switch {
+		case mr.State != "opened" && mr.State != "reopened":
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "405 Method Not Allowed"})
+			return
+		case req.SHA != "" && req.SHA != mr.SHA:
+			writeJSON(w, http.StatusConflict, map[string]string{"message": "SHA does not match HEAD of source branch: " + mr.SHA})
+			return
+		}
+		mr.Squash = mr.Squash || req.Squash
+		mr.ShouldRemoveSourceBranch = req.ShouldRemoveSourceBranch
+		whenSucceeds := req.MergeWhenPipelineSucceeds
+		if version == APIv3 {
+			whenSucceeds = req.MergeWhenBuildSucceeds
+		}
+		if whenSucceeds && mr.Pipeline != nil && mr.Pipeline.Status == "running" {
+			mr.MergeWhenPipelineSucceeds = true
+		} else {
+			mr.State = "merged"
+		}
+		writeJSON(w, http.StatusOK, mr)
+	case (action == "approve" || action == "unapprove") && r.Method == "POST" && version == APIv4:
+		if p.approved[mr.ID] == (action == "approve") {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "401 Unauthorized"})
+			return
+		}
+		p.approved[mr.ID] = action == "approve"
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"iid": mr.IID})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "404 Not Found"})
+	}
+}
+
+// updateMergeRequest sets the fields of the request on the merge request,
+// as updateIssue does on issues.
+func (f *fakeGitLab) updateMergeRequest(p *fakeProject, mr *MergeRequest, req *fakeMergeRequestRequest) (int, string) {
+	issue := fakeMergeRequestIssue(mr)
+	if status, msg := f.updateIssue(p, issue, &req.fakeIssueRequest); status != http.StatusOK {
+		return status, msg
+	}
+	mr.Title, mr.Description, mr.Labels = issue.Title, issue.Description, issue.Labels
+	mr.Milestone, mr.Assignee, mr.State, mr.UpdatedAt = issue.Milestone, issue.Assignee, issue.State, issue.UpdatedAt
+	if req.TargetBranch != nil {
+		mr.TargetBranch = *req.TargetBranch
+	}
+	if req.RemoveSourceBranch != nil {
+		mr.ForceRemoveSourceBranch = *req.RemoveSourceBranch
+	}
+	if req.Squash != nil {
+		mr.Squash = *req.Squash
+	}
+	return http.StatusOK, ""
+}
+
+// fakeMergeRequestIssue returns the fields a merge request
+// has in common with issues, as an issue.
+func fakeMergeRequestIssue(mr *MergeRequest) *gogitlab.Issue {
+	return &gogitlab.Issue{
+		ID:          mr.ID,
+		IID:         mr.IID,
+		Title:       mr.Title,
+		Description: mr.Description,
+		Labels:      mr.Labels,
+		Milestone:   mr.Milestone,
+		Assignee:    mr.Assignee,
+		State:       mr.State,
+		UpdatedAt:   mr.UpdatedAt,
+	}
+}
+
+// hasLabel returns true if the label is in labels.
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLabels splits the labels of an API request.
+func splitLabels(s string) []string {
+	labels := []string{}
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// fakeIssueMatches returns true if the issue matches the filters
+// of the list issues API in the query.
+func fakeIssueMatches(issue *gogitlab.Issue, q url.Values) bool {
+	switch q.Get("state") {
+	case "opened":
+		if issue.State == "closed" {
+			return false
+		}
+	case "closed":
+		if issue.State != "closed" {
+			return false
+		}
+	}
+	if labels := q.Get("labels"); labels != "" {
+		for _, l := range strings.Split(labels, ",") {
+			found := false
+			for _, il := range issue.Labels {
+				found = found || il == l
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	if m := q.Get("milestone"); m != "" && (issue.Milestone == nil || issue.Milestone.Title != m) {
+		return false
+	}
+	if a := q.Get("assignee_id"); a != "" && (issue.Assignee == nil || strconv.Itoa(issue.Assignee.ID) != a) {
+		return false
+	}
+	if s := q.Get("search"); s != "" && !strings.Contains(issue.Title, s) && !strings.Contains(issue.Description, s) {
+		return false
+	}
+	if iid := q.Get("iid"); iid != "" && strconv.Itoa(issue.IID) != iid {
+		return false
+	}
+	return true
+}
+
+// fakeListedLabel is a label in a list of labels on v4, that also has
+// the labels of the ancestor groups unless include_ancestor_groups is
+// false. IsProjectLabel is only set for the labels of a project.
+type fakeListedLabel struct {
+	*gogitlab.Label
+	IsProjectLabel *bool `json:"is_project_label,omitempty"`
+}
+
+// serveLabels serves the labels of a project p or group g. The issues
+// and merge requests of the projects are counted on v3, or if asked
+// with with_counts, like GitLab 12.2 and newer.
+func (f *fakeGitLab) serveLabels(w http.ResponseWriter, r *http.Request, version string, p *fakeProject, g *fakeGroup) {
+	var (
+		labels    *[]*gogitlab.Label
+		projects  []*fakeProject
+		namespace string
+	)
+	if p != nil {
+		labels, projects, namespace = &p.labels, []*fakeProject{p}, p.project.Namespace.Path
+	} else {
+		labels, projects, namespace = &g.labels, f.groupProjects(g.group.Path), path.Dir(g.group.Path)
+	}
+	var opt gogitlab.UpdateLabelOptions
+	switch r.Method {
+	case "POST", "PUT":
+		if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+	case "DELETE":
+		if name := r.URL.Query().Get("name"); name != "" {
+			opt.Name = &name
+		}
+	}
+	if r.Method != "GET" && (opt.Name == nil || *opt.Name == "") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is missing"})
+		return
+	}
+	if opt.Color != nil && *opt.Color != "" && !regexp.MustCompile(`^#[0-9a-fA-F]{6}$`).MatchString(*opt.Color) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "color is invalid"})
+		return
+	}
+
+	find := func(name string) int {
+		for i, l := range *labels {
+			if l.Name == name {
+				return i
+			}
+		}
+		return -1
+	}
+
+	switch r.Method {
+	case "GET":
+		sorted := append([]*gogitlab.Label(nil), (*labels)...)
+		sort.Sort(labelsByName(sorted))
+		if version == APIv3 || r.URL.Query().Get("with_counts") == "true" {
+			for i, l := range sorted {
+				counted := *l
+				counted.OpenIssuesCount, counted.ClosedIssuesCount, counted.OpenMergeRequestsCount = countLabel(projects, l.Name)
+				sorted[i] = &counted
+			}
+		}
+		if version == APIv3 {
+			writeJSON(w, http.StatusOK, sorted)
+			return
+		}
+		isProjectLabel := func(project bool) *bool {
+			if p == nil {
+				return nil
+			}
+			return &project
+		}
+		var listed []*fakeListedLabel
+		for _, l := range sorted {
+			listed = append(listed, &fakeListedLabel{l, isProjectLabel(true)})
+		}
+		if r.URL.Query().Get("include_ancestor_groups") != "false" || f.IgnoreAncestorGroups {
+			for _, l := range f.ancestorGroupLabels(namespace) {
+				listed = append(listed, &fakeListedLabel{l, isProjectLabel(false)})
+			}
+		}
+		writePage(w, r, f.PerPage, len(listed), func(from, to int) interface{} {
+			return listed[from:to]
+		})
+	case "POST":
+		if find(*opt.Name) != -1 {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": "Label already exists"})
+			return
+		}
+		if opt.Color == nil || *opt.Color == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "color is missing"})
+			return
+		}
+		l := &gogitlab.Label{Name: *opt.Name, Color: *opt.Color}
+		if opt.Description != nil {
+			l.Description = *opt.Description
+		}
+		(*labels) = append((*labels), l)
+		writeJSON(w, http.StatusCreated, l)
+	case "PUT":
+		i := find(*opt.Name)
+		if i == -1 {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Label Not Found"})
+			return
+		}
+		l := *(*labels)[i]
+		if opt.NewName != nil && *opt.NewName != "" {
+			if j := find(*opt.NewName); j != -1 && j != i {
+				writeJSON(w, http.StatusConflict, map[string]string{"message": "Label already exists"})
+				return
+			}
+			l.Name = *opt.NewName
+		}
+		if opt.Color != nil && *opt.Color != "" {
+			l.Color = *opt.Color
+		}
+		if opt.Description != nil {
+			l.Description = *opt.Description
+		}
+		(*labels)[i] = &l
+		writeJSON(w, http.StatusOK, &l)
+	case "DELETE":
+		i := find(*opt.Name)
+		if i == -1 {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Label Not Found"})
+			return
+		}
+		l := (*labels)[i]
+		(*labels) = append((*labels)[:i], (*labels)[i+1:]...)
+		writeJSON(w, http.StatusOK, l)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// countLabel returns the number of open and closed issues, and open
+// merge requests, of the projects that have the label.
+func countLabel(projects []*fakeProject, name string) (openIssues, closedIssues, openMRs int) {
+	for _, p := range projects {
+		for _, issue := range p.issues {
+			switch {
+			case !hasLabel(issue.Labels, name):
+			case issue.State == "closed":
+				closedIssues++
+			default:
+				openIssues++
+			}
+		}
+		for _, mr := range p.mrs {
+			if hasLabel(mr.Labels, name) && (mr.State == "opened" || mr.State == "reopened") {
+				openMRs++
+			}
+		}
+	}
+	return
+}
+
+// servePromote moves a project label to the group of the project,
+// like GitLab's promote API, if the group was added with AddGroup.
+func (f *fakeGitLab) servePromote(w http.ResponseWriter, p *fakeProject, name string) {
+	i := -1
+	for j, l := range p.labels {
+		if l.Name == name {
+			i = j
+		}
+	}
+	if i == -1 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "404 Label Not Found"})
+		return
+	}
+	g := f.groups[p.project.Namespace.Path]
+	if g == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "project is not in a group"})
+		return
+	}
+	l := p.labels[i]
+	p.labels = append(p.labels[:i], p.labels[i+1:]...)
+	exists := false
+	for _, gl := range g.labels {
+		exists = exists || gl.Name == l.Name
+	}
+	if !exists {
+		g.labels = append(g.labels, l)
+	}
+	writeJSON(w, http.StatusOK, l)
+}
+
+func (f *fakeGitLab) sortedProjects() []*gogitlab.Project {
+	var projects []*gogitlab.Project
+	for _, p := range f.projects {
+		projects = append(projects, p.project)
+	}
+	sort.Sort(projectsByID(projects))
+	return projects
+}
+
+// writePage writes one page of a list of total items, setting
+// the pagination headers the way GitLab does.
+func writePage(w http.ResponseWriter, r *http.Request, defaultPerPage, total int, slice func(from, to int) interface{}) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	pages := (total + perPage - 1) / perPage
+	if pages == 0 {
+		pages = 1
+	}
+	from, to := (page-1)*perPage, page*perPage
+	if from > total {
+		from = total
+	}
+	if to > total {
+		to = total
+	}
+	h := w.Header()
+	h.Set("X-Page", strconv.Itoa(page))
+	h.Set("X-Per-Page", strconv.Itoa(perPage))
+	h.Set("X-Total", strconv.Itoa(total))
+	h.Set("X-Total-Pages", strconv.Itoa(pages))
+	if page < pages {
+		h.Set("X-Next-Page", strconv.Itoa(page+1))
+	}
+	if page > 1 {
+		h.Set("X-Prev-Page", strconv.Itoa(page-1))
+	}
+	writeJSON(w, http.StatusOK, slice(from, to))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(fmt.Sprintf("fake gitlab: %v", err))
+	}
+}
+
+type labelsByName []*gogitlab.Label
+
+func (l labelsByName) Len() int           { return len(l) }
+func (l labelsByName) Less(i, j int) bool { return l[i].Name < l[j].Name }
+func (l labelsByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+type projectsByID []*gogitlab.Project
+
+func (p projectsByID) Len() int           { return len(p) }
+func (p projectsByID) Less(i, j int) bool { return p[i].ID < p[j].ID }
+func (p projectsByID) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
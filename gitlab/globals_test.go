@@ -16,25 +16,37 @@ var (
 	GitLabToken  = os.Getenv("GITLAB_TOKEN")
 	GitLabAPIURL *url.URL
 	GitLabClient *Client
+
+	// GitLabFake is the fake server the tests run against
+	// when GITLAB_URL is not set.
+	GitLabFake *fakeGitLab
 )
 
 func init() {
+	if GitLabURI == "" {
+		GitLabFake = newFakeGitLab()
+		GitLabFake.AddGlobalLabel("bug", "#ff0000", "represents a bug")
+		GitLabFake.AddGlobalLabel("feature", "#000000", "represents a feature")
+		GitLabURI, GitLabToken = GitLabFake.URL, GitLabFake.Token
+	}
+
 	var err error
 	if GitLabAPIURL, err = url.Parse(strings.TrimSuffix(GitLabURI, "/")); err != nil {
 		panic(err)
 	}
 
-	if GitLabClient, err = NewClient(GitLabAPIURL, GitLabToken); err != nil {
+	if GitLabClient, err = NewClient(GitLabAPIURL, GitLabToken, &Options{
+		APIVersion: os.Getenv("GITLAB_API_VERSION"),
+		Insecure:   os.Getenv("GITLAB_INSECURE") != "",
+		CAFile:     os.Getenv("GITLAB_CA_FILE"),
+	}); err != nil {
 		panic(err)
 	}
 }
 
 func before(tb testing.TB) {
-	if GitLabURI == "" {
-		tb.Skip("GITLAB_URL is not set, should be set in order to run tests (e.g. 'https://gitlab.com')")
-	}
 	if GitLabToken == "" {
-		tb.Skip("GITLAB_TOKEN is not set, should be set in order to run tests")
+		tb.Skip("GITLAB_TOKEN is not set, should be set in order to run tests against GITLAB_URL")
 	}
 }
 
@@ -67,3 +79,12 @@ func deleteProject(tb testing.TB, proj *gogitlab.Project) {
 		tb.Errorf("%s:%v %v", path.Base(file), line, err)
 	}
 }
+
+// fake returns the fake server the tests run against,
+// or skips the test if they run against a real GitLab instance.
+func fake(tb testing.TB) *fakeGitLab {
+	if GitLabFake == nil {
+		tb.Skip("test needs the fake GitLab server, unset GITLAB_URL to run it")
+	}
+	return GitLabFake
+}
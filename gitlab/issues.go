@@ -0,0 +1,278 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+type Issues struct {
+	*gogitlab.IssuesService
+	client *Client
+}
+
+// Issue states, for ListIssuesOptions.State.
+const (
+	IssueOpened = "opened"
+	IssueClosed = "closed"
+)
+
+// ListIssuesOptions are the filters for Issues.List(). The empty
+// value of a field means no filter.
+type ListIssuesOptions struct {
+	State     string   // IssueOpened or IssueClosed
+	Labels    []string // issues that have all of them
+	Milestone string   // the title of the milestone
+	Assignee  string   // the username of the assignee
+	Search    string   // in the title and description
+}
+
+// IssueOptions are the fields of an issue to set with Issues.Create()
+// and Issues.Update(). The nil fields are not set.
+type IssueOptions struct {
+	Title       *string
+	Description *string
+	Labels      *[]string
+	// Milestone is the title of the milestone, "" to remove it.
+	Milestone *string
+	// Assignee is the username of the assignee, "" to unassign.
+	Assignee *string
+}
+
+type listIssuesOptions struct {
+	State      string `url:"state,omitempty"`
+	Labels     string `url:"labels,omitempty"`
+	Milestone  string `url:"milestone,omitempty"`
+	AssigneeID int    `url:"assignee_id,omitempty"`
+	Search     string `url:"search,omitempty"`
+	IID        int    `url:"iid,omitempty"` // v3
+}
+
+type issueRequest struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Labels      *string `json:"labels,omitempty"`
+	MilestoneID *int    `json:"milestone_id,omitempty"`
+	AssigneeID  *int    `json:"assignee_id,omitempty"`
+	StateEvent  *string `json:"state_event,omitempty"`
+}
+
+// List returns the issues of a project that match the options,
+// from all the pages, newest first.
+// It returns a *NotFound error if the assignee doesn't exist.
+func (srv *Issues) List(pid interface{}, opts *ListIssuesOptions) ([]*gogitlab.Issue, error) {
+	if opts == nil {
+		opts = &ListIssuesOptions{}
+	}
+	opt := &listIssuesOptions{
+		State:     opts.State,
+		Labels:    strings.Join(opts.Labels, ","),
+		Milestone: opts.Milestone,
+		Search:    opts.Search,
+	}
+	if opts.Assignee != "" {
+		id, err := srv.client.userID(opts.Assignee)
+		if err != nil {
+			return nil, err
+		}
+		opt.AssigneeID = id
+	}
+	return srv.list(pid, opt)
+}
+
+// Get returns the issue of a project with the given IID, the number of
+// the issue in the project (e.g. 12 for #12).
+// It returns a *NotFound error if the project has no such issue.
+func (srv *Issues) Get(pid interface{}, iid int) (*gogitlab.Issue, error) {
+	if srv.client.APIVersion == APIv3 {
+		// v3 gets issues by their global ID
+		issues, err := srv.list(pid, &listIssuesOptions{IID: iid})
+		if err != nil {
+			return nil, err
+		}
+		if len(issues) == 0 {
+			return nil, &NotFound{fmt.Sprintf("issue #%d was not found", iid)}
+		}
+		return issues[0], nil
+	}
+	issue, resp, err := srv.GetIssue(pid, iid)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, &NotFound{fmt.Sprintf("issue #%d was not found", iid)}
+		}
+		return nil, err
+	}
+	return issue, nil
+}
+
+// Create creates an issue in a project. The title is required.
+func (srv *Issues) Create(pid interface{}, opts *IssueOptions) (*gogitlab.Issue, error) {
+	if opts == nil || opts.Title == nil || *opts.Title == "" {
+		return nil, fmt.Errorf("the issue has no title")
+	}
+	req, err := srv.request(pid, opts)
+	if err != nil {
+		return nil, err
+	}
+	return srv.do("POST", fmt.Sprintf("projects/%s/issues", projectPath(pid)), req)
+}
+
+// Update sets the given fields of the issue with the given IID.
+func (srv *Issues) Update(pid interface{}, iid int, opts *IssueOptions) (*gogitlab.Issue, error) {
+	if opts == nil {
+		opts = &IssueOptions{}
+	}
+	req, err := srv.request(pid, opts)
+	if err != nil {
+		return nil, err
+	}
+	return srv.update(pid, iid, req)
+}
+
+// Close closes the issue with the given IID.
+func (srv *Issues) Close(pid interface{}, iid int) (*gogitlab.Issue, error) {
+	return srv.update(pid, iid, &issueRequest{StateEvent: gogitlab.String("close")})
+}
+
+// Reopen reopens the closed issue with the given IID.
+func (srv *Issues) Reopen(pid interface{}, iid int) (*gogitlab.Issue, error) {
+	return srv.update(pid, iid, &issueRequest{StateEvent: gogitlab.String("reopen")})
+}
+
+func (srv *Issues) update(pid interface{}, iid int, req *issueRequest) (*gogitlab.Issue, error) {
+	id := iid
+	if srv.client.APIVersion == APIv3 {
+		issue, err := srv.Get(pid, iid)
+		if err != nil {
+			return nil, err
+		}
+		id = issue.ID
+	}
+	issue, err := srv.do("PUT", fmt.Sprintf("projects/%s/issues/%d", projectPath(pid), id), req)
+	if _, ok := err.(*NotFound); ok {
+		err = &NotFound{fmt.Sprintf("issue #%d was not found", iid)}
+	}
+	return issue, err
+}
+
+// request returns the API request for the options, with the IDs
+// of the milestone and the assignee.
+func (srv *Issues) request(pid interface{}, opts *IssueOptions) (*issueRequest, error) {
+	req := &issueRequest{
+		Title:       opts.Title,
+		Description: opts.Description,
+	}
+	if opts.Labels != nil {
+		labels := strings.Join(*opts.Labels, ",")
+		req.Labels = &labels
+	}
+	if opts.Milestone != nil {
+		id := 0
+		if *opts.Milestone != "" {
+			m, err := srv.client.milestone(pid, *opts.Milestone)
+			if err != nil {
+				return nil, err
+			}
+			id = m.ID
+		}
+		req.MilestoneID = &id
+	}
+	if opts.Assignee != nil {
+		id := 0
+		if *opts.Assignee != "" {
+			var err error
+			if id, err = srv.client.userID(*opts.Assignee); err != nil {
+				return nil, err
+			}
+		}
+		req.AssigneeID = &id
+	}
+	return req, nil
+}
+
+func (srv *Issues) do(method, path string, req *issueRequest) (*gogitlab.Issue, error) {
+	r, err := srv.client.NewRequest(method, path, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	issue := new(gogitlab.Issue)
+	resp, err := srv.client.Do(r, issue)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, &NotFound{err.Error()}
+		}
+		return nil, err
+	}
+	return issue, nil
+}
+
+// list returns the issues of a project from all the pages.
+func (srv *Issues) list(pid interface{}, opt *listIssuesOptions) ([]*gogitlab.Issue, error) {
+	path := fmt.Sprintf("projects/%s/issues", projectPath(pid))
+	var all []*gogitlab.Issue
+	for page := 1; page > 0; {
+		req, err := srv.client.NewRequest("GET", path, opt, []gogitlab.OptionFunc{withPage(page, 100)})
+		if err != nil {
+			return nil, err
+		}
+		var issues []*gogitlab.Issue
+		resp, err := srv.client.Do(req, &issues)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, issues...)
+		page = resp.NextPage
+	}
+	return all, nil
+}
+
+// userID returns the ID of the user with the given username.
+// It returns a *NotFound error if there's no such user.
+func (c *Client) userID(username string) (int, error) {
+	username = strings.TrimPrefix(username, "@")
+	users, _, err := c.Users.ListUsers(&gogitlab.ListUsersOptions{Username: &username})
+	if err != nil {
+		return 0, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Username, username) {
+			return u.ID, nil
+		}
+	}
+	return 0, &NotFound{fmt.Sprintf("user '%s' was not found", username)}
+}
+
+// milestone returns the milestone of a project with the given title.
+// It returns a *NotFound error if there's no such milestone.
+func (c *Client) milestone(pid interface{}, title string) (*gogitlab.Milestone, error) {
+	path := fmt.Sprintf("projects/%s/milestones", projectPath(pid))
+	for page := 1; page > 0; {
+		req, err := c.NewRequest("GET", path, nil, []gogitlab.OptionFunc{withPage(page, 100)})
+		if err != nil {
+			return nil, err
+		}
+		var milestones []*gogitlab.Milestone
+		resp, err := c.Do(req, &milestones)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range milestones {
+			if m.Title == title {
+				return m, nil
+			}
+		}
+		page = resp.NextPage
+	}
+	return nil, &NotFound{fmt.Sprintf("milestone '%s' was not found", title)}
+}
+
+// projectPath returns the project id or path to use in API paths.
+func projectPath(pid interface{}) string {
+	if s, ok := pid.(string); ok {
+		return url.QueryEscape(s)
+	}
+	return fmt.Sprint(pid)
+}
@@ -0,0 +1,129 @@
+package gitlab
+
+import (
+	"testing"
+)
+
+func TestIssues_List(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/issues-list")
+	f.AddIssue(proj.ID, "first bug", "bug")
+	f.AddIssue(proj.ID, "second bug", "bug", "critical")
+	f.AddIssue(proj.ID, "feature", "enhancement")
+
+	for _, version := range []string{APIv3, APIv4} {
+		c := f.Client(t, version)
+		type _test struct {
+			opts *ListIssuesOptions
+			want []string
+		}
+		tests := []*_test{
+			&_test{nil, []string{"feature", "second bug", "first bug"}},
+			&_test{&ListIssuesOptions{Labels: []string{"bug"}}, []string{"second bug", "first bug"}},
+			&_test{&ListIssuesOptions{Labels: []string{"bug", "critical"}}, []string{"second bug"}},
+			&_test{&ListIssuesOptions{Search: "first"}, []string{"first bug"}},
+			&_test{&ListIssuesOptions{State: IssueClosed}, nil},
+		}
+		for _, test := range tests {
+			issues, err := c.Issues.List(proj.ID, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, i := range issues {
+				titles = append(titles, i.Title)
+			}
+			if len(titles) != len(test.want) {
+				t.Errorf("%s: expecting %v for %+v, got %v", version, test.want, test.opts, titles)
+				continue
+			}
+			for i := range titles {
+				if titles[i] != test.want[i] {
+					t.Errorf("%s: expecting %v for %+v, got %v", version, test.want, test.opts, titles)
+					break
+				}
+			}
+		}
+	}
+
+	if _, err := GitLabClient.Issues.List(proj.ID, &ListIssuesOptions{Assignee: "nobody"}); err == nil {
+		t.Error("expecting error for an unknown assignee")
+	}
+}
+
+func TestIssues_CreateUpdate(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/issues-create")
+	f.AddMilestone(proj.ID, "v1.0")
+
+	for _, version := range []string{APIv3, APIv4} {
+		c := f.Client(t, version)
+		title, desc := "crash on start ("+version+")", "It crashes."
+		labels := []string{"bug", "critical"}
+		milestone, assignee := "v1.0", "@"+f.User
+		issue, err := c.Issues.Create(proj.ID, &IssueOptions{
+			Title:       &title,
+			Description: &desc,
+			Labels:      &labels,
+			Milestone:   &milestone,
+			Assignee:    &assignee,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issue.Title != title || issue.Description != desc || len(issue.Labels) != 2 ||
+			issue.Milestone == nil || issue.Milestone.Title != milestone ||
+			issue.Assignee == nil || issue.Assignee.Username != f.User {
+			t.Errorf("%s: unexpected issue %+v", version, issue)
+		}
+
+		got, err := c.Issues.Get(proj.ID, issue.IID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != issue.ID {
+			t.Errorf("%s: expecting issue %d, got %d", version, issue.ID, got.ID)
+		}
+
+		// an empty milestone and assignee remove them, other fields are kept
+		newTitle, none := "crashes on start ("+version+")", ""
+		noLabels := []string{}
+		issue, err = c.Issues.Update(proj.ID, issue.IID, &IssueOptions{
+			Title:     &newTitle,
+			Labels:    &noLabels,
+			Milestone: &none,
+			Assignee:  &none,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issue.Title != newTitle || issue.Description != desc || len(issue.Labels) != 0 ||
+			issue.Milestone != nil || issue.Assignee != nil {
+			t.Errorf("%s: unexpected updated issue %+v", version, issue)
+		}
+
+		if issue, err = c.Issues.Close(proj.ID, issue.IID); err != nil || issue.State != IssueClosed {
+			t.Errorf("%s: expecting the issue to be closed, got %v, %v", version, issue, err)
+		}
+		if issue, err = c.Issues.Reopen(proj.ID, issue.IID); err != nil || issue.State == IssueClosed {
+			t.Errorf("%s: expecting the issue to be reopened, got %v, %v", version, issue, err)
+		}
+
+		if _, err := c.Issues.Get(proj.ID, 1000); err == nil {
+			t.Errorf("%s: expecting error for a missing issue", version)
+		} else if _, ok := err.(*NotFound); !ok {
+			t.Errorf("%s: expecting not found, got %v", version, err)
+		}
+		if _, err := c.Issues.Close(proj.ID, 1000); err == nil {
+			t.Errorf("%s: expecting error when closing a missing issue", version)
+		}
+	}
+
+	unknown, empty := "v2.0", ""
+	if _, err := GitLabClient.Issues.Create(proj.ID, &IssueOptions{Title: &unknown, Milestone: &unknown}); err == nil {
+		t.Error("expecting error for an unknown milestone")
+	}
+	if _, err := GitLabClient.Issues.Create(proj.ID, &IssueOptions{Title: &empty}); err == nil {
+		t.Error("expecting error for an issue without a title")
+	}
+}
@@ -2,7 +2,9 @@ package gitlab
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
+	"sync"
 
 	"strings"
 
@@ -16,6 +18,148 @@ type Labels struct {
 	client *Client
 }
 
+// Strategies for copying a label that already exists in the target project.
+const (
+	ConflictSkip      = "skip"      // keep the existing label
+	ConflictOverwrite = "overwrite" // update the color and description of the existing label
+	ConflictRename    = "rename"    // create the label with a new name, e.g. 'bug (2)'
+	ConflictFail      = "fail"      // don't copy anything
+)
+
+// LabelChange is a change to the labels of a project. It creates
+// the New label if Old is nil, deletes the Old label if New is nil,
+// skips the label if Old and New are the same and updates Old to New
+// otherwise.
+type LabelChange struct {
+	Old *gogitlab.Label
+	New *gogitlab.Label
+}
+
+// Action returns what the change does: "create", "update", "delete" or "skip".
+func (c *LabelChange) Action() string {
+	switch {
+	case c.Old == nil:
+		return "create"
+	case c.New == nil:
+		return "delete"
+	case *c.Old == *c.New:
+		return "skip"
+	}
+	return "update"
+}
+
+// Name returns the name of the label the change applies to.
+func (c *LabelChange) Name() string {
+	if c.Old != nil {
+		return c.Old.Name
+	}
+	return c.New.Name
+}
+
+func (c *LabelChange) String() string {
+	switch c.Action() {
+	case "create":
+		return fmt.Sprintf("create '%s' (%s)", c.New.Name, c.New.Color)
+	case "delete":
+		return fmt.Sprintf("delete '%s'", c.Old.Name)
+	case "skip":
+		return fmt.Sprintf("skip '%s', already exists", c.Old.Name)
+	}
+	var s []string
+	if c.New.Name != c.Old.Name {
+		s = append(s, fmt.Sprintf("rename '%s' → '%s'", c.Old.Name, c.New.Name))
+	}
+	if c.New.Color != c.Old.Color {
+		s = append(s, fmt.Sprintf("recolor '%s' %s → %s", c.Old.Name, c.Old.Color, c.New.Color))
+	}
+	if c.New.Description != c.Old.Description {
+		s = append(s, fmt.Sprintf("describe '%s' '%s' → '%s'", c.Old.Name, c.Old.Description, c.New.Description))
+	}
+	return strings.Join(s, ", ")
+}
+
+// WithCounts asks ListLabels for the number of issues and merge requests
+// of every label, that GitLab 12.2 and newer only return when asked.
+func WithCounts() gogitlab.OptionFunc {
+	return func(req *http.Request) error {
+		q := req.URL.Query()
+		q.Set("with_counts", "true")
+		req.URL.RawQuery = q.Encode()
+		return nil
+	}
+}
+
+// projectLabel is a label as listed by the v4 API for a project, which
+// also lists the labels of the groups the project inherits, unless asked
+// not to (GitLab 13.6 and newer).
+type projectLabel struct {
+	gogitlab.Label
+	IsProjectLabel *bool `json:"is_project_label"`
+}
+
+// ListLabels returns all the labels of a project or Group, without
+// the labels of the ancestor groups. Unlike *LabelsService.ListLabels(),
+// it follows the pagination that the v4 API uses for labels.
+func (srv *Labels) ListLabels(pid interface{}, options ...gogitlab.OptionFunc) ([]*gogitlab.Label, *gogitlab.Response, error) {
+	var all []*gogitlab.Label
+	page := 1
+	for {
+		var (
+			labels []*gogitlab.Label
+			resp   *gogitlab.Response
+			err    error
+		)
+		opts := append([]gogitlab.OptionFunc{withPage(page, 100), withoutAncestorGroups}, options...)
+		if g, ok := pid.(Group); ok {
+			resp, err = srv.groupLabelsRequest("GET", g, nil, opts, &labels)
+		} else if srv.client.APIVersion == APIv4 {
+			labels, resp, err = srv.listProjectLabels(pid, opts)
+		} else {
+			labels, resp, err = srv.LabelsService.ListLabels(pid, opts...)
+		}
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, labels...)
+		if resp.NextPage == 0 {
+			return all, resp, nil
+		}
+		page = resp.NextPage
+	}
+}
+
+// listProjectLabels returns a page of the labels of a project on v4,
+// leaving out the ones of the groups, that older GitLab versions list
+// regardless of include_ancestor_groups.
+func (srv *Labels) listProjectLabels(pid interface{}, options []gogitlab.OptionFunc) ([]*gogitlab.Label, *gogitlab.Response, error) {
+	req, err := srv.client.NewRequest("GET", fmt.Sprintf("projects/%s/labels", projectPath(pid)), nil, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	var listed []*projectLabel
+	resp, err := srv.client.Do(req, &listed)
+	if err != nil {
+		return nil, resp, err
+	}
+	var labels []*gogitlab.Label
+	for _, l := range listed {
+		if l.IsProjectLabel == nil || *l.IsProjectLabel {
+			label := l.Label
+			labels = append(labels, &label)
+		}
+	}
+	return labels, resp, nil
+}
+
+// withoutAncestorGroups asks for the labels of a project or group
+// without the labels of its ancestor groups.
+func withoutAncestorGroups(req *http.Request) error {
+	q := req.URL.Query()
+	q.Set("include_ancestor_groups", "false")
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
 // UpdateWithRegex updates label(s) by a given regex in a given project. The difference
 // between *LabelsService.UpdateLabel() and this is that opts.Name is a regexp string,
 // so you can do things like replace all labels like 'type:bug' with 'type/bug' using:
@@ -25,68 +169,114 @@ type Labels struct {
 //
 // If at least one label fails to update, it will return an error.
 func (srv *Labels) UpdateWithRegex(pid interface{}, opts *gogitlab.UpdateLabelOptions) error {
-	re, err := regexp.Compile(*opts.Name)
+	changes, err := srv.PlanUpdateWithRegex(pid, opts)
+	if err != nil {
+		return err
+	}
+	return srv.ApplyChanges(pid, changes)
+}
+
+// PlanUpdateWithRegex returns the changes that UpdateWithRegex would
+// make, without making them. Labels that wouldn't change are skipped.
+func (srv *Labels) PlanUpdateWithRegex(pid interface{}, opts *gogitlab.UpdateLabelOptions) ([]*LabelChange, error) {
+	re, err := compileRegex(*opts.Name)
 	if err != nil {
-		return fmt.Errorf("'%s' is not a valid Go regexp: %v\n"+
-			"See https://golang.org/pkg/regexp/syntax/", opts.Name, err)
+		return nil, err
 	}
-	repl := opts.NewName
 	labels, _, err := srv.ListLabels(pid)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var errs []string
+	var changes []*LabelChange
 	for _, label := range labels {
-		if re.MatchString(label.Name) {
-			opts.Name = &label.Name
-			var newName string
-			if repl != nil && *repl != "" {
-				newName = re.ReplaceAllString(label.Name, *repl)
-			} else {
-				newName = ""
-			}
-			opts.NewName = &newName
-			if _, _, err := srv.UpdateLabel(pid, opts); err != nil {
-				errs = append(errs, fmt.Sprintf("'%s' failed to update: %v", label.Name, err))
-			}
+		if !re.MatchString(label.Name) {
+			continue
+		}
+		l := *label
+		if opts.NewName != nil && *opts.NewName != "" {
+			l.Name = re.ReplaceAllString(label.Name, *opts.NewName)
+		}
+		if opts.Color != nil && *opts.Color != "" {
+			l.Color = *opts.Color
+		}
+		if opts.Description != nil && *opts.Description != "" {
+			l.Description = *opts.Description
+		}
+		if l.Name != label.Name || l.Color != label.Color || l.Description != label.Description {
+			changes = append(changes, &LabelChange{Old: label, New: &l})
 		}
 	}
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to update (some) labels with the following errors:\n%s", strings.Join(errs, "\n"))
-	}
-	return nil
+	return changes, nil
 }
 
 // DeleteWithRegex deletes labels from a project, optionally by matching
 // against a Regexp pattern.
+//
+// If at least one label fails to delete, it will return an error.
 func (srv *Labels) DeleteWithRegex(pid interface{}, pattern string) error {
-	re, err := regexp.Compile(pattern)
+	changes, err := srv.PlanDeleteWithRegex(pid, pattern)
 	if err != nil {
-		return fmt.Errorf("'%s' is not a valid Go regexp: %v\n"+
-			"See https://golang.org/pkg/regexp/syntax/", pattern, err)
+		return err
+	}
+	return srv.ApplyChanges(pid, changes)
+}
+
+// PlanDeleteWithRegex returns the changes that DeleteWithRegex would
+// make, without making them.
+func (srv *Labels) PlanDeleteWithRegex(pid interface{}, pattern string) ([]*LabelChange, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
 	}
 	labels, _, err := srv.ListLabels(pid)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	var changes []*LabelChange
 	for _, label := range labels {
 		if pattern == "" || re.MatchString(label.Name) {
-			_, err := srv.DeleteLabel(pid, &gogitlab.DeleteLabelOptions{Name: &label.Name})
-			if err != nil {
-				return err
-			}
+			changes = append(changes, &LabelChange{Old: label})
 		}
 	}
-	return nil
+	return changes, nil
 }
 
 // CopyGlobalLabelsTo copies the global labels to the given project id.
-// Since there's no API in GitLab for accessing global labels, it
-// creates a temporary project that should have all global labels copied into
-// and then reads the labels from it. It deletes the temporary project when done.
+// The labels that already exist in the project are handled according
+// to the onConflict strategy, see ConflictSkip and co.
 //
 // If at least one label fails to copy, it will return an error.
-func (srv *Labels) CopyGlobalLabelsTo(pid interface{}) error {
+func (srv *Labels) CopyGlobalLabelsTo(pid interface{}, onConflict string) error {
+	changes, err := srv.PlanCopyGlobalLabelsTo(pid, onConflict)
+	if err != nil {
+		return err
+	}
+	return srv.ApplyChanges(pid, changes)
+}
+
+// PlanCopyGlobalLabelsTo returns the changes that CopyGlobalLabelsTo
+// would make, without making them. Note that it still creates (and
+// deletes) a temporary project to read the global labels, see GlobalLabels().
+func (srv *Labels) PlanCopyGlobalLabelsTo(pid interface{}, onConflict string) ([]*LabelChange, error) {
+	labels, err := srv.GlobalLabels()
+	if err != nil {
+		return nil, err
+	}
+	return srv.PlanCopy(labels, pid, onConflict)
+}
+
+// GlobalLabels returns the global labels, that GitLab copies into
+// every new project. The API doesn't expose them, so they are read from
+// a temporary project, see globalLabelsFromTemporaryProject().
+func (srv *Labels) GlobalLabels() ([]*gogitlab.Label, error) {
+	return srv.globalLabelsFromTemporaryProject()
+}
+
+// globalLabelsFromTemporaryProject returns the global labels by creating
+// a temporary project, that should have all global labels copied into,
+// and then reading the labels from it. It deletes the temporary project
+// when done, even if the process is interrupted in the meantime.
+func (srv *Labels) globalLabelsFromTemporaryProject() ([]*gogitlab.Label, error) {
 	name := "temporary-copy-globals-from-" + RandomString(4)
 	desc := "Temporary repository to copy global labels from"
 	proj, _, err := srv.client.Projects.CreateProject(&gogitlab.CreateProjectOptions{
@@ -94,38 +284,169 @@ func (srv *Labels) CopyGlobalLabelsTo(pid interface{}) error {
 		Description: &desc,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() {
-		if _, err := srv.client.Projects.DeleteProject(proj.ID); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-		}
-	}()
+	var once sync.Once
+	deleteProject := func() {
+		once.Do(func() {
+			if _, err := srv.client.Projects.DeleteProject(proj.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to delete temporary project '%s': %v\n",
+					proj.PathWithNamespace, err)
+			}
+		})
+	}
+	defer cleanupOnSignal(deleteProject)()
+	defer deleteProject()
 
-	return srv.CopyLabels(proj.ID, pid)
+	labels, _, err := srv.ListLabels(proj.ID)
+	return labels, err
 }
 
 // CopyLabels copies the labels from a project into another one,
-// based on the given pid's.
+// based on the given pid's. The labels that already exist in the
+// target project are handled according to the onConflict strategy,
+// see ConflictSkip and co.
 //
 // If at least one label fails to copy, it will return an error.
-func (srv *Labels) CopyLabels(from, to interface{}) error {
-	labels, _, err := srv.ListLabels(from)
+func (srv *Labels) CopyLabels(from, to interface{}, onConflict string) error {
+	changes, err := srv.PlanCopyLabels(from, to, onConflict)
 	if err != nil {
 		return err
 	}
-	var errs []string
+	return srv.ApplyChanges(to, changes)
+}
+
+// PlanCopyLabels returns the changes that CopyLabels would make,
+// without making them.
+func (srv *Labels) PlanCopyLabels(from, to interface{}, onConflict string) ([]*LabelChange, error) {
+	labels, _, err := srv.ListLabels(from)
+	if err != nil {
+		return nil, err
+	}
+	return srv.PlanCopy(labels, to, onConflict)
+}
+
+// PlanCopy returns the changes that copy the given labels into
+// the project, handling the existing ones according to onConflict.
+func (srv *Labels) PlanCopy(labels []*gogitlab.Label, pid interface{}, onConflict string) ([]*LabelChange, error) {
+	switch onConflict {
+	case ConflictSkip, ConflictOverwrite, ConflictRename, ConflictFail:
+	default:
+		return nil, fmt.Errorf("unknown conflict strategy '%s', must be one of: %s, %s, %s, %s",
+			onConflict, ConflictSkip, ConflictOverwrite, ConflictRename, ConflictFail)
+	}
+	targetLabels, _, err := srv.ListLabels(pid)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*gogitlab.Label)
+	for _, label := range targetLabels {
+		existing[label.Name] = label
+	}
+	var (
+		changes   []*LabelChange
+		conflicts []string
+	)
 	for _, label := range labels {
-		if _, _, err := srv.CreateLabel(to, &gogitlab.CreateLabelOptions{
-			Name:        &label.Name,
-			Color:       &label.Color,
-			Description: &label.Description,
-		}); err != nil {
-			errs = append(errs, fmt.Sprintf("'%s' failed to create: %v", label.Name, err))
+		target := existing[label.Name]
+		if target == nil {
+			changes = append(changes, &LabelChange{New: label})
+			existing[label.Name] = label
+			continue
+		}
+		switch onConflict {
+		case ConflictSkip:
+			changes = append(changes, &LabelChange{Old: target, New: target})
+		case ConflictOverwrite:
+			l := *target
+			l.Color = label.Color
+			l.Description = label.Description
+			changes = append(changes, &LabelChange{Old: target, New: &l})
+		case ConflictRename:
+			l := *label
+			for i := 2; existing[l.Name] != nil; i++ {
+				l.Name = fmt.Sprintf("%s (%d)", label.Name, i)
+			}
+			changes = append(changes, &LabelChange{New: &l})
+			existing[l.Name] = &l
+		case ConflictFail:
+			conflicts = append(conflicts, label.Name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("labels already exist in the target: '%s'", strings.Join(conflicts, "', '"))
+	}
+	return changes, nil
+}
+
+// ApplyChanges makes the given changes to the labels of a project.
+// The updates are made first, then the creates and then the deletes, so
+// that e.g. a label can be created with the old name of a renamed one.
+// The changes of each kind are made concurrently, with at most
+// Client.Concurrency requests at a time, waiting when GitLab's rate
+// limit is reached.
+//
+// If at least one change fails, it will return an error, that lists
+// the failed changes in the order they were given.
+func (srv *Labels) ApplyChanges(pid interface{}, changes []*LabelChange) error {
+	errs := make([]error, len(changes))
+	for _, action := range []string{"update", "create", "delete"} {
+		var todo []int
+		for i, c := range changes {
+			if c.Action() == action {
+				todo = append(todo, i)
+			}
 		}
+		ForEach(len(todo), srv.client.Concurrency, func(i int) {
+			c := changes[todo[i]]
+			errs[todo[i]] = srv.applyChange(pid, c)
+		})
 	}
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to copy (some) labels with the following errors:\n%s", strings.Join(errs, "\n"))
+	var msgs []string
+	for i, err := range errs {
+		if err != nil {
+			c := changes[i]
+			msgs = append(msgs, fmt.Sprintf("'%s' failed to %s: %v", c.Name(), c.Action(), err))
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("failed to change (some) labels with the following errors:\n%s", strings.Join(msgs, "\n"))
 	}
 	return nil
 }
+
+func (srv *Labels) applyChange(pid interface{}, c *LabelChange) error {
+	var err error
+	switch c.Action() {
+	case "create":
+		_, _, err = srv.CreateLabel(pid, &gogitlab.CreateLabelOptions{
+			Name:        &c.New.Name,
+			Color:       &c.New.Color,
+			Description: &c.New.Description,
+		})
+	case "delete":
+		_, err = srv.DeleteLabel(pid, &gogitlab.DeleteLabelOptions{Name: &c.Old.Name})
+	default:
+		opts := &gogitlab.UpdateLabelOptions{Name: &c.Old.Name}
+		if c.New.Name != c.Old.Name {
+			opts.NewName = &c.New.Name
+		}
+		if c.New.Color != c.Old.Color {
+			opts.Color = &c.New.Color
+		}
+		if c.New.Description != c.Old.Description {
+			opts.Description = &c.New.Description
+		}
+		_, _, err = srv.UpdateLabel(pid, opts)
+	}
+	return err
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid Go regexp: %v\n"+
+			"See https://golang.org/pkg/regexp/syntax/", pattern, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,194 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+// Group is the path or ID of a group. It can be given to the Labels
+// methods instead of a project id, to manage the labels of the group,
+// which are inherited by all its projects. Group labels need API v4.
+type Group string
+
+// CreateLabel is the same as *LabelsService.CreateLabel(),
+// except that pid can also be a Group.
+func (srv *Labels) CreateLabel(pid interface{}, opt *gogitlab.CreateLabelOptions, options ...gogitlab.OptionFunc) (*gogitlab.Label, *gogitlab.Response, error) {
+	g, ok := pid.(Group)
+	if !ok {
+		return srv.LabelsService.CreateLabel(pid, opt, options...)
+	}
+	l := new(gogitlab.Label)
+	resp, err := srv.groupLabelsRequest("POST", g, opt, options, l)
+	if err != nil {
+		return nil, resp, err
+	}
+	return l, resp, nil
+}
+
+// UpdateLabel is the same as *LabelsService.UpdateLabel(),
+// except that pid can also be a Group.
+func (srv *Labels) UpdateLabel(pid interface{}, opt *gogitlab.UpdateLabelOptions, options ...gogitlab.OptionFunc) (*gogitlab.Label, *gogitlab.Response, error) {
+	g, ok := pid.(Group)
+	if !ok {
+		return srv.LabelsService.UpdateLabel(pid, opt, options...)
+	}
+	l := new(gogitlab.Label)
+	resp, err := srv.groupLabelsRequest("PUT", g, opt, options, l)
+	if err != nil {
+		return nil, resp, err
+	}
+	return l, resp, nil
+}
+
+// DeleteLabel is the same as *LabelsService.DeleteLabel(),
+// except that pid can also be a Group.
+func (srv *Labels) DeleteLabel(pid interface{}, opt *gogitlab.DeleteLabelOptions, options ...gogitlab.OptionFunc) (*gogitlab.Response, error) {
+	g, ok := pid.(Group)
+	if !ok {
+		return srv.LabelsService.DeleteLabel(pid, opt, options...)
+	}
+	return srv.groupLabelsRequest("DELETE", g, opt, options, nil)
+}
+
+func (srv *Labels) groupLabelsRequest(method string, g Group, opt interface{}, options []gogitlab.OptionFunc, v interface{}) (*gogitlab.Response, error) {
+	u := fmt.Sprintf("groups/%s/labels", url.QueryEscape(string(g)))
+	req, err := srv.client.NewRequest(method, u, opt, options)
+	if err != nil {
+		return nil, err
+	}
+	return srv.client.Do(req, v)
+}
+
+// PlanPromote returns the labels of a project that Promote would
+// move to the group, the ones matching the regexp pattern (or all
+// if the pattern is empty).
+func (srv *Labels) PlanPromote(pid interface{}, pattern string) ([]*gogitlab.Label, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	labels, _, err := srv.ListLabels(pid)
+	if err != nil {
+		return nil, err
+	}
+	var promote []*gogitlab.Label
+	for _, label := range labels {
+		if pattern == "" || re.MatchString(label.Name) {
+			promote = append(promote, label)
+		}
+	}
+	return promote, nil
+}
+
+// CanPromote returns true if GitLab's promote API can move the labels
+// of a project to the group, which keeps them on issues and merge
+// requests. It needs GitLab 12.3 or newer, and the group of the project,
+// since that's where the API promotes to. It returns an error if the
+// group, given by its path, is not an ancestor of the project.
+func (srv *Labels) CanPromote(pid interface{}, group Group) (bool, error) {
+	project, _, err := srv.client.Projects.GetProject(pid)
+	if err != nil {
+		return false, err
+	}
+	name := strings.ToLower(project.PathWithNamespace)
+	if !strings.HasPrefix(name, strings.ToLower(string(group))+"/") {
+		return false, fmt.Errorf("group '%s' doesn't contain project '%s'", group, project.PathWithNamespace)
+	}
+	if srv.client.APIVersion == APIv3 || strings.Contains(name[len(group)+1:], "/") {
+		return false, nil
+	}
+	return srv.hasPromoteAPI()
+}
+
+// Promote turns the given project labels into labels of the group,
+// which must be an ancestor of the project, and removes them from the
+// project. It uses GitLab's promote API if it can (see CanPromote).
+// Otherwise, if recreate is true, it creates the group label (if
+// missing) and deletes the project label, which removes it from the
+// issues and merge requests that have it, and if it's false it returns
+// an error without changing anything.
+//
+// If at least one label fails to promote, it will return an error.
+func (srv *Labels) Promote(pid interface{}, group Group, labels []*gogitlab.Label, recreate bool) error {
+	useAPI, err := srv.CanPromote(pid, group)
+	if err != nil {
+		return err
+	}
+	if !useAPI && !recreate {
+		return fmt.Errorf("the labels can't be promoted to '%s' with the promote API, which needs GitLab 12.3 or newer "+
+			"and the group of the project, and recreating them would remove them from issues and merge requests", group)
+	}
+	existing := make(map[string]bool)
+	if !useAPI {
+		groupLabels, _, err := srv.ListLabels(group)
+		if err != nil {
+			return err
+		}
+		for _, label := range groupLabels {
+			existing[label.Name] = true
+		}
+	}
+	var errs []string
+	for _, label := range labels {
+		var err error
+		if useAPI {
+			err = srv.promote(pid, label)
+		} else {
+			if !existing[label.Name] {
+				_, _, err = srv.CreateLabel(group, &gogitlab.CreateLabelOptions{
+					Name:        &label.Name,
+					Color:       &label.Color,
+					Description: &label.Description,
+				})
+			}
+			if err == nil {
+				_, err = srv.DeleteLabel(pid, &gogitlab.DeleteLabelOptions{Name: &label.Name})
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("'%s' failed to promote: %v", label.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to promote (some) labels with the following errors:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// promote calls the promote API for a project label.
+func (srv *Labels) promote(pid interface{}, label *gogitlab.Label) error {
+	project, ok := pid.(string)
+	if !ok {
+		project = fmt.Sprint(pid)
+	}
+	u := fmt.Sprintf("projects/%s/labels/%s/promote", url.QueryEscape(project), url.QueryEscape(label.Name))
+	req, err := srv.client.NewRequest("PUT", u, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = srv.client.Do(req, nil)
+	return err
+}
+
+// hasPromoteAPI returns true if the server is GitLab 12.3 or newer,
+// that added the promote API.
+func (srv *Labels) hasPromoteAPI() (bool, error) {
+	req, err := srv.client.NewRequest("GET", "version", nil, nil)
+	if err != nil {
+		return false, err
+	}
+	var v struct {
+		Version string `json:"version"`
+	}
+	if _, err := srv.client.Do(req, &v); err != nil {
+		return false, fmt.Errorf("failed to get the GitLab version: %v", err)
+	}
+	var major, minor int
+	if _, err := fmt.Sscanf(v.Version, "%d.%d", &major, &minor); err != nil {
+		return false, fmt.Errorf("unknown GitLab version '%s'", v.Version)
+	}
+	return major > 12 || (major == 12 && minor >= 3), nil
+}
@@ -0,0 +1,198 @@
+package gitlab
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+func TestLabels_Group(t *testing.T) {
+	f := fake(t)
+	f.AddGroup("labels-group")
+	c := f.Client(t, APIv4)
+	g := Group("labels-group")
+
+	if err := c.Labels.Sync(g, []*LabelSpec{
+		{Name: "bug", Color: "#ff0000"},
+		{Name: "feature", Color: "#00ff00"},
+	}, false); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := c.Labels.PlanDeleteWithRegex(g, "^feat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, []string{"delete 'feature'"})
+	if err := c.Labels.ApplyChanges(g, changes); err != nil {
+		t.Fatal(err)
+	}
+	name, col := "bug", "#000000"
+	if _, _, err := c.Labels.UpdateLabel(g, &gogitlab.UpdateLabelOptions{Name: &name, Color: &col}); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, _, err := c.Labels.ListLabels(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || *labels[0] != (gogitlab.Label{Name: "bug", Color: "#000000"}) {
+		t.Errorf("expecting only the 'bug' label recolored, got %v", labels)
+	}
+}
+
+func TestLabels_Promote(t *testing.T) {
+	f := fake(t)
+	f.AddGroup("promote-group")
+	f.ServerVersion = "12.3.0"
+	defer func() { f.ServerVersion = "9.5.0" }()
+	c := f.Client(t, APIv4)
+
+	type _test struct {
+		path     string
+		version  string
+		recreate bool
+		useAPI   bool
+	}
+	tests := []*_test{
+		&_test{"promote-group/repo-api", "12.3.0", false, true},
+		&_test{"promote-group/repo-old", "11.10.1", true, false},
+		// the API only promotes to the group of the project
+		&_test{"promote-group/sub/repo-nested", "12.3.0", true, false},
+	}
+	for _, test := range tests {
+		f.ServerVersion = test.version
+		proj := f.AddProject(test.path)
+		addLabel(t, proj, "type/bug", "#ff0000", "A bug")
+		addLabel(t, proj, "type/feature", "#00ff00", "")
+
+		if useAPI, err := c.Labels.CanPromote(proj.ID, Group("promote-group")); err != nil || useAPI != test.useAPI {
+			t.Errorf("%s: expecting CanPromote %v, got %v, %v", test.path, test.useAPI, useAPI, err)
+		}
+		labels, err := c.Labels.PlanPromote(proj.ID, "^type/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(labels) != 2 {
+			t.Fatalf("%s: expecting 2 labels to promote, got %v", test.path, labels)
+		}
+		if !test.useAPI {
+			if err := c.Labels.Promote(proj.ID, Group("promote-group"), labels, false); err == nil {
+				t.Errorf("%s: expecting error without recreate", test.path)
+			}
+			if kept, _ := c.Labels.PlanPromote(proj.ID, "^type/"); len(kept) != 2 {
+				t.Errorf("%s: expecting the labels to be kept without recreate, got %v", test.path, kept)
+			}
+		}
+		before := len(f.Requests())
+		if err := c.Labels.Promote(proj.ID, Group("promote-group"), labels, test.recreate); err != nil {
+			t.Fatal(err)
+		}
+		promoted := 0
+		for _, req := range f.Requests()[before:] {
+			if strings.HasSuffix(req, "/promote") {
+				promoted++
+			}
+		}
+		if test.useAPI != (promoted == 2) {
+			t.Errorf("%s: expecting the promote API to be used %v, got %d requests", test.path, test.useAPI, promoted)
+		}
+
+		for _, l := range getLabels(t, proj.ID) {
+			if l.Name == "type/bug" || l.Name == "type/feature" {
+				t.Errorf("%s: label '%s' is still in the project", test.path, l.Name)
+			}
+		}
+		expected := []gogitlab.Label{
+			{Name: "type/bug", Color: "#ff0000", Description: "A bug"},
+			{Name: "type/feature", Color: "#00ff00"},
+		}
+		groupLabels := f.GroupLabels("promote-group")
+		if len(groupLabels) != len(expected) {
+			t.Fatalf("%s: expecting group labels %v, got %v", test.path, expected, groupLabels)
+		}
+		for i, l := range groupLabels {
+			if *l != expected[i] {
+				t.Errorf("%s: expecting group label %v, got %v", test.path, expected[i], *l)
+			}
+		}
+	}
+
+	// a label deleted after the plan fails, instead of being recreated
+	f.ServerVersion = "12.3.0"
+	proj := f.AddProject("promote-group/repo-deleted")
+	addLabel(t, proj, "deleted", "#0000ff", "")
+	labels, err := c.Labels.PlanPromote(proj.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "deleted"
+	if _, err := c.Labels.DeleteLabel(proj.ID, &gogitlab.DeleteLabelOptions{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Labels.Promote(proj.ID, Group("promote-group"), labels, false); err == nil {
+		t.Error("expecting error for a deleted label")
+	}
+	for _, l := range f.GroupLabels("promote-group") {
+		if l.Name == "deleted" {
+			t.Error("expecting the deleted label not to be created in the group")
+		}
+	}
+
+	other := f.AddProject("other-group/repo")
+	if err := c.Labels.Promote(other.ID, Group("promote-group"), labels, true); err == nil {
+		t.Error("expecting error for a group that doesn't contain the project")
+	}
+}
+
+func TestLabels_InheritedGroupLabels(t *testing.T) {
+	f := fake(t)
+	f.AddGroup("inherit-group")
+	f.AddGroup("inherit-group/sub")
+	proj := f.AddProject("inherit-group/sub/repo")
+	c := f.Client(t, APIv4)
+	for _, g := range []Group{"inherit-group", "inherit-group/sub"} {
+		if err := c.Labels.Sync(g, []*LabelSpec{{Name: "group/" + string(g), Color: "#ff0000"}}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addLabel(t, proj, "type/bug", "#00ff00", "")
+
+	// GitLab before 13.6 lists them regardless of include_ancestor_groups
+	for _, ignored := range []bool{false, true} {
+		f.IgnoreAncestorGroups = ignored
+		labels, _, err := c.Labels.ListLabels(proj.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range labels {
+			if strings.HasPrefix(l.Name, "group/") {
+				t.Errorf("ignored %v: expecting only the project labels, got %v", ignored, labels)
+				break
+			}
+		}
+		changes, err := c.Labels.PlanDeleteWithRegex(proj.ID, "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkChanges(t, changes, []string{"delete 'type/bug'"})
+		if changes, err = c.Labels.PlanPrune(proj.ID, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		for _, change := range changes {
+			if strings.HasPrefix(change.Name(), "group/") {
+				t.Errorf("ignored %v: expecting the group labels not to be pruned, got %v", ignored, changes)
+				break
+			}
+		}
+		labels, _, err = c.Labels.ListLabels(Group("inherit-group/sub"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ignored && (len(labels) != 1 || labels[0].Name != "group/inherit-group/sub") {
+			t.Errorf("expecting only the labels of the subgroup, got %v", labels)
+		}
+	}
+	f.IgnoreAncestorGroups = false
+}
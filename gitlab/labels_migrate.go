@@ -0,0 +1,230 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+// Kinds of the items that have labels.
+const (
+	KindIssue        = "issue"
+	KindMergeRequest = "merge_request"
+)
+
+// LabelledItem is an issue or merge request, with its labels.
+type LabelledItem struct {
+	Kind      string     `json:"kind"` // KindIssue or KindMergeRequest
+	ID        int        `json:"id"`
+	IID       int        `json:"iid"`
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	Labels    []string   `json:"labels"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// String returns the item as GitLab refers to it, e.g. '#12' for an
+// issue and '!12' for a merge request.
+func (i *LabelledItem) String() string {
+	if i.Kind == KindMergeRequest {
+		return fmt.Sprintf("!%d", i.IID)
+	}
+	return fmt.Sprintf("#%d", i.IID)
+}
+
+// LabelMigration is what Labels.Migrate() does: move the issues and merge
+// requests from the From labels to the To label, creating it if missing.
+type LabelMigration struct {
+	From []*gogitlab.Label
+	To   *gogitlab.Label
+	// Create is true if the To label doesn't exist yet.
+	Create bool
+	Moves  []*LabelMove
+}
+
+// LabelMove changes the labels of an issue or merge request from Old to New.
+type LabelMove struct {
+	Item *LabelledItem
+	Old  []string
+	New  []string
+}
+
+// PlanMigrate returns the migration of all the issues and merge requests
+// of a project that have labels matching the regexp pattern, except the
+// to label, to the to label. If the to label doesn't exist, it is created
+// with the color and description of the first label it replaces.
+func (srv *Labels) PlanMigrate(pid interface{}, pattern, to string) (*LabelMigration, error) {
+	if to == "" {
+		return nil, fmt.Errorf("no label to migrate to")
+	}
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	labels, _, err := srv.ListLabels(pid)
+	if err != nil {
+		return nil, err
+	}
+	m := &LabelMigration{}
+	for _, label := range labels {
+		if label.Name == to {
+			m.To = label
+		} else if re.MatchString(label.Name) {
+			m.From = append(m.From, label)
+		}
+	}
+	if m.To == nil {
+		m.Create = true
+		m.To = &gogitlab.Label{Name: to, Color: "#428bca"}
+		if len(m.From) > 0 {
+			m.To.Color, m.To.Description = m.From[0].Color, m.From[0].Description
+		}
+	}
+
+	items, err := srv.LabelledItems(pid)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		var labels []string
+		moved, has := false, false
+		for _, l := range item.Labels {
+			switch {
+			case l == to:
+				has = true
+				labels = append(labels, l)
+			case re.MatchString(l):
+				moved = true
+			default:
+				labels = append(labels, l)
+			}
+		}
+		if !moved {
+			continue
+		}
+		if !has {
+			labels = append(labels, to)
+		}
+		m.Moves = append(m.Moves, &LabelMove{Item: item, Old: item.Labels, New: labels})
+	}
+	return m, nil
+}
+
+// Migrate makes the migration planned by PlanMigrate(): creates the
+// target label if needed and changes the labels of the issues and merge
+// requests. It doesn't delete the labels migrated from.
+//
+// If at least one item fails to change, it will return an error.
+func (srv *Labels) Migrate(pid interface{}, m *LabelMigration) error {
+	if m.Create && len(m.Moves) > 0 {
+		if err := srv.ApplyChanges(pid, []*LabelChange{{New: m.To}}); err != nil {
+			return err
+		}
+	}
+	errs := make([]error, len(m.Moves))
+	ForEach(len(m.Moves), srv.client.Concurrency, func(i int) {
+		errs[i] = srv.client.setLabels(pid, m.Moves[i])
+	})
+	var msgs []string
+	for i, err := range errs {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("%s %s failed to relabel: %v",
+				strings.Replace(m.Moves[i].Item.Kind, "_", " ", -1), m.Moves[i].Item, err))
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("failed to relabel (some) items with the following errors:\n%s", strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+// LabelledItems returns all the issues and merge requests of a project,
+// or a Group, open and closed, with their labels.
+func (srv *Labels) LabelledItems(pid interface{}) ([]*LabelledItem, error) {
+	base := "projects/" + projectPath(pid)
+	if g, ok := pid.(Group); ok {
+		base = "groups/" + url.QueryEscape(string(g))
+	}
+	var all []*LabelledItem
+	for _, kind := range []string{KindIssue, KindMergeRequest} {
+		path := fmt.Sprintf("%s/%ss", base, kind)
+		for page := 1; page > 0; {
+			req, err := srv.client.NewRequest("GET", path, nil, []gogitlab.OptionFunc{withPage(page, 100)})
+			if err != nil {
+				return nil, err
+			}
+			var items []*LabelledItem
+			resp, err := srv.client.Do(req, &items)
+			if err != nil {
+				if resp != nil && resp.StatusCode == http.StatusNotFound {
+					return nil, &NotFound{err.Error()}
+				}
+				return nil, err
+			}
+			for _, item := range items {
+				item.Kind = kind
+			}
+			all = append(all, items...)
+			page = resp.NextPage
+		}
+	}
+	return all, nil
+}
+
+// setLabels changes the labels of an issue or merge request from the
+// Old to the New labels of the move. On v4 it only adds and removes the
+// labels that differ, to keep the labels changed since the move was
+// planned, and replaces them all only if the server ignored that (older
+// GitLab versions). v3 always replaces them all.
+func (c *Client) setLabels(pid interface{}, move *LabelMove) error {
+	id := move.Item.IID
+	if c.APIVersion == APIv3 {
+		// v3 gets issues and merge requests by their global ID
+		id = move.Item.ID
+	}
+	path := fmt.Sprintf("projects/%s/%ss/%d", projectPath(pid), move.Item.Kind, id)
+	if c.APIVersion == APIv4 {
+		add, remove := labelsDiff(move.Old, move.New), labelsDiff(move.New, move.Old)
+		req, err := c.NewRequest("PUT", path, &struct {
+			AddLabels    string `json:"add_labels,omitempty"`
+			RemoveLabels string `json:"remove_labels,omitempty"`
+		}{strings.Join(add, ","), strings.Join(remove, ",")}, nil)
+		if err != nil {
+			return err
+		}
+		var item LabelledItem
+		_, err = c.Do(req, &item)
+		// all the labels added and none of the removed ones left
+		if err != nil || (len(labelsDiff(item.Labels, add)) == 0 && len(labelsDiff(item.Labels, remove)) == len(remove)) {
+			return err
+		}
+	}
+	req, err := c.NewRequest("PUT", path, &struct {
+		Labels string `json:"labels"`
+	}{strings.Join(move.New, ",")}, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.Do(req, nil)
+	return err
+}
+
+// labelsDiff returns the labels that are in b but not in a.
+func labelsDiff(a, b []string) []string {
+	in := make(map[string]bool)
+	for _, l := range a {
+		in[l] = true
+	}
+	var diff []string
+	for _, l := range b {
+		if !in[l] {
+			diff = append(diff, l)
+		}
+	}
+	return diff
+}
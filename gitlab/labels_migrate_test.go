@@ -0,0 +1,108 @@
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+func TestLabels_Migrate(t *testing.T) {
+	f := fake(t)
+
+	for _, version := range []string{APIv3, APIv4} {
+		c := f.Client(t, version)
+		proj := f.AddProject("group/migrate-labels-" + version)
+		if err := c.Labels.DeleteWithRegex(proj.ID, ""); err != nil {
+			t.Fatal(err)
+		}
+		addLabel(t, proj, "bug", "#ff0000", "A bug")
+		addLabel(t, proj, "defect", "#ff0000", "")
+		addLabel(t, proj, "Bug", "#ff0000", "")
+		addLabel(t, proj, "feature", "#00ff00", "")
+		f.AddIssue(proj.ID, "defect only", "defect", "feature")
+		f.AddIssue(proj.ID, "both", "bug", "Bug")
+		f.AddIssue(proj.ID, "already migrated", "bug")
+		f.AddIssue(proj.ID, "unrelated", "feature")
+		f.AddMergeRequest(proj.ID, "fix", "defect")
+
+		m, err := c.Labels.PlanMigrate(proj.ID, "(?i)^(bug|defect)$", "bug")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Create || m.To.Name != "bug" || len(m.From) != 2 {
+			t.Errorf("%s: expecting to migrate 'Bug' and 'defect' to the existing 'bug', got %+v", version, m)
+		}
+		var moves []string
+		for _, move := range m.Moves {
+			moves = append(moves, move.Item.String()+" "+strings.Join(move.New, ","))
+		}
+		// the items are listed newest first, issues before merge requests
+		if got, want := strings.Join(moves, "; "), "#2 bug; #1 feature,bug; !1 bug"; got != want {
+			t.Errorf("%s: expecting moves '%s', got '%s'", version, want, got)
+		}
+
+		if err := c.Labels.Migrate(proj.ID, m); err != nil {
+			t.Fatal(err)
+		}
+		for iid, want := range map[int]string{1: "feature,bug", 2: "bug", 3: "bug", 4: "feature"} {
+			if got := strings.Join(f.Issue(proj.ID, iid).Labels, ","); got != want {
+				t.Errorf("%s: expecting issue #%d to have '%s', got '%s'", version, iid, want, got)
+			}
+		}
+		if got := strings.Join(f.MergeRequest(proj.ID, 1).Labels, ","); got != "bug" {
+			t.Errorf("%s: expecting the merge request to have 'bug', got '%s'", version, got)
+		}
+
+		// to a new label
+		if m, err = c.Labels.PlanMigrate(proj.ID, "^feature$", "type:feature"); err != nil {
+			t.Fatal(err)
+		}
+		if !m.Create || m.To.Color != "#00ff00" || len(m.Moves) != 2 {
+			t.Errorf("%s: expecting to create 'type:feature' and move 2 issues, got %+v", version, m)
+		}
+		if err := c.Labels.Migrate(proj.ID, m); err != nil {
+			t.Fatal(err)
+		}
+		labelsExist(t, proj, []*gogitlab.Label{&gogitlab.Label{Name: "type:feature", Color: "#00ff00"}})
+	}
+
+	if _, err := GitLabClient.Labels.PlanMigrate(1, "(", "bug"); err == nil {
+		t.Error("expecting error for an invalid regexp")
+	}
+	if _, err := GitLabClient.Labels.PlanMigrate(1, "bug", ""); err == nil {
+		t.Error("expecting error without a target label")
+	}
+}
+
+func TestLabels_MigrateChangedItems(t *testing.T) {
+	f := fake(t)
+	c := f.Client(t, APIv4)
+
+	for _, ignored := range []bool{false, true} {
+		f.IgnoreLabelChanges = ignored
+		proj := f.AddProject(fmt.Sprintf("group/migrate-changed-%v", ignored))
+		f.AddIssue(proj.ID, "defect", "defect", "feature")
+
+		m, err := c.Labels.PlanMigrate(proj.ID, "^defect$", "bug")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// changed after the plan
+		f.SetIssueLabels(proj.ID, 1, "defect", "feature", "urgent")
+		if err := c.Labels.Migrate(proj.ID, m); err != nil {
+			t.Fatal(err)
+		}
+		// only the labels that differ are changed, unless the server
+		// ignores add_labels and remove_labels
+		want := "feature,urgent,bug"
+		if ignored {
+			want = "feature,bug"
+		}
+		if got := strings.Join(f.Issue(proj.ID, 1).Labels, ","); got != want {
+			t.Errorf("ignored %v: expecting the issue to have '%s', got '%s'", ignored, want, got)
+		}
+	}
+	f.IgnoreLabelChanges = false
+}
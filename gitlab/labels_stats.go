@@ -0,0 +1,142 @@
+package gitlab
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+// LabelStats is how much a label is used, see Labels.Stats().
+type LabelStats struct {
+	Label               *gogitlab.Label
+	OpenIssues          int
+	ClosedIssues        int
+	OpenMergeRequests   int
+	ClosedMergeRequests int // including the merged ones
+	// LastUsed is the last update of an issue or merge request with the
+	// label, nil if none has it or if the date of one of them is unknown.
+	LastUsed *time.Time
+	// Similar are the other labels whose names differ only in case or
+	// separators, e.g. 'type:bug' and 'Type/Bug'.
+	Similar []string
+}
+
+// Unused returns true if no issue or merge request has the label.
+func (s *LabelStats) Unused() bool {
+	return s.OpenIssues+s.ClosedIssues+s.OpenMergeRequests+s.ClosedMergeRequests == 0
+}
+
+// Stats returns the usage of every label of a project, or a Group,
+// sorted by name. It reads all the issues and merge requests, so it
+// can take a while for big projects.
+func (srv *Labels) Stats(pid interface{}) ([]*LabelStats, error) {
+	labels, _, err := srv.ListLabels(pid)
+	if err != nil {
+		return nil, err
+	}
+	items, err := srv.LabelledItems(pid)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*LabelStats, len(labels))
+	byName := make(map[string]*LabelStats)
+	similar := make(map[string][]string)
+	// the labels of items without dates, last used at an unknown time
+	unknown := make(map[string]bool)
+	for i, l := range labels {
+		stats[i] = &LabelStats{Label: l}
+		byName[l.Name] = stats[i]
+		key := similarityKey(l.Name)
+		similar[key] = append(similar[key], l.Name)
+	}
+	for _, item := range items {
+		for _, name := range item.Labels {
+			s := byName[name]
+			if s == nil {
+				continue
+			}
+			open := item.State != "closed" && item.State != "merged"
+			switch {
+			case item.Kind == KindIssue && open:
+				s.OpenIssues++
+			case item.Kind == KindIssue:
+				s.ClosedIssues++
+			case open:
+				s.OpenMergeRequests++
+			default:
+				s.ClosedMergeRequests++
+			}
+			used := item.UpdatedAt
+			if used == nil {
+				used = item.CreatedAt
+			}
+			if used == nil {
+				unknown[name] = true
+			} else if s.LastUsed == nil || used.After(*s.LastUsed) {
+				s.LastUsed = used
+			}
+		}
+	}
+	for _, s := range stats {
+		if unknown[s.Label.Name] {
+			s.LastUsed = nil
+		}
+		for _, name := range similar[similarityKey(s.Label.Name)] {
+			if name != s.Label.Name {
+				s.Similar = append(s.Similar, name)
+			}
+		}
+	}
+	sort.Sort(labelStatsByName(stats))
+	return stats, nil
+}
+
+// PlanPrune returns the changes that delete the labels of a project,
+// or a Group, that no issue or merge request has used since the given
+// time, including the ones never used. The labels used at an unknown
+// time, or by an open issue or merge request, are kept.
+func (srv *Labels) PlanPrune(pid interface{}, since time.Time) ([]*LabelChange, error) {
+	stats, err := srv.Stats(pid)
+	if err != nil {
+		return nil, err
+	}
+	var changes []*LabelChange
+	for _, s := range stats {
+		if s.OpenIssues+s.OpenMergeRequests > 0 {
+			continue
+		}
+		if s.Unused() || (s.LastUsed != nil && s.LastUsed.Before(since)) {
+			changes = append(changes, &LabelChange{Old: s.Label})
+		}
+	}
+	return changes, nil
+}
+
+// similarityKey returns the name of a label in lower case, with the
+// separators, e.g. ':', '/' or ' ', replaced by '/'. Labels with the
+// same key are near-duplicates.
+func similarityKey(name string) string {
+	var key []rune
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if sep && len(key) > 0 {
+				key = append(key, '/')
+			}
+			key = append(key, r)
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+	return string(key)
+}
+
+type labelStatsByName []*LabelStats
+
+func (l labelStatsByName) Len() int           { return len(l) }
+func (l labelStatsByName) Less(i, j int) bool { return l[i].Label.Name < l[j].Label.Name }
+func (l labelStatsByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
@@ -0,0 +1,117 @@
+package gitlab
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLabels_Stats(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/label-stats")
+	if err := GitLabClient.Labels.DeleteWithRegex(proj.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"bug", "type:bug", "Type/Bug", "done", "feature", "stale"} {
+		addLabel(t, proj, name, "#ff0000", "")
+	}
+	f.AddIssue(proj.ID, "open bug", "bug", "type:bug")
+	closed := f.AddIssue(proj.ID, "closed bug", "bug", "done")
+	if _, err := GitLabClient.Issues.Close(proj.ID, closed.IID); err != nil {
+		t.Fatal(err)
+	}
+	f.AddMergeRequest(proj.ID, "fix", "bug", "feature")
+
+	stats, err := GitLabClient.Labels.Stats(proj.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range stats {
+		got = append(got, strings.Join([]string{
+			s.Label.Name,
+			strings.Repeat("o", s.OpenIssues) + strings.Repeat("c", s.ClosedIssues) +
+				strings.Repeat("O", s.OpenMergeRequests) + strings.Repeat("C", s.ClosedMergeRequests),
+			strings.Join(s.Similar, ","),
+		}, " "))
+		if used := !s.Unused(); used != (s.Label.Name != "stale" && s.Label.Name != "Type/Bug") {
+			t.Errorf("'%s' is used: %v", s.Label.Name, used)
+		}
+	}
+	want := []string{
+		"Type/Bug  type:bug",
+		"bug ocO ",
+		"done c ",
+		"feature O ",
+		"stale  ",
+		"type:bug o Type/Bug",
+	}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("expecting stats\n%v\ngot\n%v", want, got)
+	}
+
+	changes, err := GitLabClient.Labels.PlanPrune(proj.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, []string{"delete 'Type/Bug'", "delete 'stale'"})
+
+	// none used since a time in the future, but the ones with open
+	// issues or merge requests are still used
+	if changes, err = GitLabClient.Labels.PlanPrune(proj.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, []string{"delete 'Type/Bug'", "delete 'done'", "delete 'stale'"})
+}
+
+func TestLabels_StatsUnknownDates(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/label-stats-unknown-dates")
+	if err := GitLabClient.Labels.DeleteWithRegex(proj.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dated", "undated", "stale"} {
+		addLabel(t, proj, name, "#ff0000", "")
+	}
+	dated := f.AddIssue(proj.ID, "dated", "dated", "undated")
+	undated := f.AddIssue(proj.ID, "undated", "undated")
+	// closed, not to be kept as the labels of open issues
+	for _, iid := range []int{dated.IID, undated.IID} {
+		if _, err := GitLabClient.Issues.Close(proj.ID, iid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.ClearIssueDates(proj.ID, undated.IID)
+
+	stats, err := GitLabClient.Labels.Stats(proj.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stats {
+		if s.Label.Name == "undated" && (s.Unused() || s.LastUsed != nil) {
+			t.Errorf("expecting 'undated' to be used at an unknown time, got %+v", s)
+		}
+	}
+
+	// the labels used at an unknown time are never pruned
+	changes, err := GitLabClient.Labels.PlanPrune(proj.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, []string{"delete 'dated'", "delete 'stale'"})
+}
+
+func TestSimilarityKey(t *testing.T) {
+	for _, names := range [][2]string{
+		{"type:bug", "Type / Bug"},
+		{"priority::high", "priority-high"},
+		{"bug", "BUG"},
+	} {
+		if a, b := similarityKey(names[0]), similarityKey(names[1]); a != b {
+			t.Errorf("expecting '%s' and '%s' to be similar, got '%s' and '%s'", names[0], names[1], a, b)
+		}
+	}
+	if similarityKey("typebug") == similarityKey("type:bug") {
+		t.Error("expecting 'typebug' and 'type:bug' to be different")
+	}
+}
@@ -0,0 +1,101 @@
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+// LabelSpec is the desired state of a label, used by Sync.
+// An existing label named like one of the Aliases is renamed to Name,
+// if there's no label named Name already. Empty Color and Description
+// mean any color or description.
+type LabelSpec struct {
+	Name        string   `yaml:"name" json:"name"`
+	Color       string   `yaml:"color,omitempty" json:"color,omitempty"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Aliases     []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+}
+
+// Sync makes the labels of a project match the given specs. It creates
+// the missing labels, renames the ones named like an alias and updates
+// the color and description of the ones that differ. If prune is true,
+// it also deletes the labels that don't match any spec.
+//
+// If at least one label fails to sync, it will return an error.
+func (srv *Labels) Sync(pid interface{}, specs []*LabelSpec, prune bool) error {
+	changes, err := srv.PlanSync(pid, specs, prune)
+	if err != nil {
+		return err
+	}
+	return srv.ApplyChanges(pid, changes)
+}
+
+// PlanSync returns the changes that Sync would make, without making them.
+func (srv *Labels) PlanSync(pid interface{}, specs []*LabelSpec, prune bool) ([]*LabelChange, error) {
+	names := make(map[string]bool)
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("label without a name")
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("label '%s' is specified more than once", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+	labels, _, err := srv.ListLabels(pid)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*gogitlab.Label)
+	for _, label := range labels {
+		existing[label.Name] = label
+	}
+
+	var updates, creates, deletes []*LabelChange
+	synced := make(map[string]bool)
+	for _, spec := range specs {
+		label := existing[spec.Name]
+		if label == nil {
+			for _, alias := range spec.Aliases {
+				if l := existing[alias]; l != nil && !names[alias] && !synced[alias] {
+					label = l
+					break
+				}
+			}
+		}
+		if label == nil {
+			if spec.Color == "" {
+				return nil, fmt.Errorf("label '%s' needs a color to be created", spec.Name)
+			}
+			creates = append(creates, &LabelChange{New: &gogitlab.Label{
+				Name:        spec.Name,
+				Color:       spec.Color,
+				Description: spec.Description,
+			}})
+			continue
+		}
+		synced[label.Name] = true
+		l := *label
+		l.Name = spec.Name
+		// GitLab may return the color in another case than the manifest
+		if spec.Color != "" && !strings.EqualFold(spec.Color, label.Color) {
+			l.Color = spec.Color
+		}
+		if spec.Description != "" {
+			l.Description = spec.Description
+		}
+		if l != *label {
+			updates = append(updates, &LabelChange{Old: label, New: &l})
+		}
+	}
+	if prune {
+		for _, label := range labels {
+			if !synced[label.Name] {
+				deletes = append(deletes, &LabelChange{Old: label})
+			}
+		}
+	}
+	return append(append(updates, creates...), deletes...), nil
+}
@@ -0,0 +1,71 @@
+package gitlab
+
+import (
+	"testing"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+func TestLabels_Sync(t *testing.T) {
+	before(t)
+
+	proj := createProject(t, "temporary-sync-labels-", "Temporary repository to sync labels into")
+	defer deleteProject(t, proj)
+
+	if err := GitLabClient.Labels.DeleteWithRegex(proj.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	addLabel(t, proj, "bug", "#000000", "A bug")
+	addLabel(t, proj, "type:feature", "#00ff00", "A feature")
+	addLabel(t, proj, "stale", "#cccccc", "")
+
+	specs := []*LabelSpec{
+		&LabelSpec{Name: "bug", Color: "#ff0000", Description: "A bug"},
+		&LabelSpec{Name: "feature", Aliases: []string{"enhancement", "type:feature"}},
+		&LabelSpec{Name: "docs", Color: "#0000ff", Description: "Documentation"},
+	}
+	changes, err := GitLabClient.Labels.PlanSync(proj.ID, specs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, []string{
+		"recolor 'bug' #000000 → #ff0000",
+		"rename 'type:feature' → 'feature'",
+		"create 'docs' (#0000ff)",
+		"delete 'stale'",
+	})
+
+	if err := GitLabClient.Labels.Sync(proj.ID, specs, false); err != nil {
+		t.Fatal(err)
+	}
+	labelsExist(t, proj, []*gogitlab.Label{
+		&gogitlab.Label{Name: "bug", Color: "#ff0000", Description: "A bug"},
+		&gogitlab.Label{Name: "feature", Color: "#00ff00", Description: "A feature"},
+		&gogitlab.Label{Name: "docs", Color: "#0000ff", Description: "Documentation"},
+		&gogitlab.Label{Name: "stale"},
+	})
+
+	// once synced, only pruning is left to do
+	if changes, err = GitLabClient.Labels.PlanSync(proj.ID, specs, true); err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, []string{"delete 'stale'"})
+
+	// colors are compared regardless of case
+	specs[0].Color = "#FF0000"
+	if changes, err = GitLabClient.Labels.PlanSync(proj.ID, specs, false); err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, nil)
+
+	// invalid specs
+	for _, specs := range [][]*LabelSpec{
+		{&LabelSpec{Name: "new-label"}},
+		{&LabelSpec{Name: "bug"}, &LabelSpec{Name: "bug"}},
+		{&LabelSpec{Color: "#000000"}},
+	} {
+		if _, err := GitLabClient.Labels.PlanSync(proj.ID, specs, false); err == nil {
+			t.Errorf("expecting error for specs %v", specs)
+		}
+	}
+}
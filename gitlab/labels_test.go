@@ -1,6 +1,8 @@
 package gitlab
 
 import (
+	"fmt"
+	"net/http"
 	"testing"
 
 	"path"
@@ -93,7 +95,7 @@ func TestLabels_CopyGlobalLabelsTo(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := GitLabClient.Labels.CopyGlobalLabelsTo(proj.ID); err != nil {
+	if err := GitLabClient.Labels.CopyGlobalLabelsTo(proj.ID, ConflictFail); err != nil {
 		t.Fatal(err)
 	}
 
@@ -109,8 +111,235 @@ func TestLabels_CopyGlobalLabelsTo(t *testing.T) {
 	}
 }
 
+func TestLabels_GlobalLabels(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	f.AddGlobalLabel("bug", "#ff0000", "represents a bug")
+	c := f.Client(t, APIv4)
+
+	labels, err := c.Labels.GlobalLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || labels[0].Name != "bug" {
+		t.Errorf("expecting the 'bug' label, got %v", labels)
+	}
+	if projects := f.sortedProjects(); len(projects) != 0 {
+		t.Errorf("temporary project was not deleted: %v", projects)
+	}
+	var created int
+	for _, req := range f.Requests() {
+		if req == "POST projects" {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Errorf("expecting 1 temporary project to be created, got %d", created)
+	}
+}
+
+func TestLabels_CopyLabelsConflicts(t *testing.T) {
+	before(t)
+
+	from := createProject(t, "temporary-copy-labels-from-", "Temporary repository to copy labels from")
+	defer deleteProject(t, from)
+	to := createProject(t, "temporary-copy-labels-to-", "Temporary repository to copy labels to")
+	defer deleteProject(t, to)
+
+	for _, proj := range []*gogitlab.Project{from, to} {
+		if err := GitLabClient.Labels.DeleteWithRegex(proj.ID, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addLabel(t, from, "bug", "#ff0000", "A bug")
+	addLabel(t, from, "feature", "#00ff00", "A feature")
+	addLabel(t, to, "bug", "#000000", "Old bug")
+	addLabel(t, to, "bug (2)", "#000000", "")
+
+	tests := []struct {
+		onConflict string
+		expected   []string
+	}{
+		{ConflictSkip, []string{"skip 'bug', already exists", "create 'feature' (#00ff00)"}},
+		{ConflictOverwrite, []string{"recolor 'bug' #000000 → #ff0000, describe 'bug' 'Old bug' → 'A bug'", "create 'feature' (#00ff00)"}},
+		{ConflictRename, []string{"create 'bug (3)' (#ff0000)", "create 'feature' (#00ff00)"}},
+	}
+	for _, test := range tests {
+		changes, err := GitLabClient.Labels.PlanCopyLabels(from.ID, to.ID, test.onConflict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkChanges(t, changes, test.expected)
+	}
+	if _, err := GitLabClient.Labels.PlanCopyLabels(from.ID, to.ID, ConflictFail); err == nil {
+		t.Errorf("expecting error with '%s' strategy", ConflictFail)
+	}
+
+	// copying is idempotent with skip
+	for i := 0; i < 2; i++ {
+		if err := GitLabClient.Labels.CopyLabels(from.ID, to.ID, ConflictSkip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	labelsExist(t, to, []*gogitlab.Label{
+		&gogitlab.Label{Name: "bug", Color: "#000000", Description: "Old bug"},
+		&gogitlab.Label{Name: "feature", Color: "#00ff00", Description: "A feature"},
+	})
+}
+
+func TestLabels_ListLabelsPaginated(t *testing.T) {
+	f := fake(t)
+
+	proj := f.AddProject("group/paginated-labels")
+	for i := 0; i < 45; i++ {
+		addLabel(t, proj, fmt.Sprintf("label-%02d", i), "#000000", "")
+	}
+
+	for _, version := range []string{APIv3, APIv4} {
+		labels, _, err := f.Client(t, version).Labels.ListLabels(proj.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 45 labels plus the global ones
+		if len(labels) != 47 {
+			t.Errorf("%s: expecting 47 labels, got %d", version, len(labels))
+		}
+	}
+}
+
+func TestLabels_ListLabelsWithCounts(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/labels-counts")
+	addLabel(t, proj, "counted", "#ff0000", "")
+	f.AddIssue(proj.ID, "crash", "counted")
+	closed := f.AddIssue(proj.ID, "typo", "counted")
+	f.AddMergeRequest(proj.ID, "fix crash", "counted")
+	c := f.Client(t, APIv4)
+	if _, err := c.Issues.Close(proj.ID, closed.IID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, withCounts := range []bool{false, true} {
+		var opts []gogitlab.OptionFunc
+		if withCounts {
+			opts = append(opts, WithCounts())
+		}
+		labels, _, err := c.Labels.ListLabels(proj.ID, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := gogitlab.Label{Name: "counted", Color: "#ff0000"}
+		if withCounts {
+			want.OpenIssuesCount, want.ClosedIssuesCount, want.OpenMergeRequestsCount = 1, 1, 1
+		}
+		var found bool
+		for _, l := range labels {
+			if l.Name == "counted" {
+				found = true
+				if *l != want {
+					t.Errorf("with counts %v: expecting %+v, got %+v", withCounts, want, l)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("with counts %v: expecting the 'counted' label, got %v", withCounts, labels)
+		}
+	}
+}
+
+func TestLabels_UpdateWithRegexErrors(t *testing.T) {
+	f := fake(t)
+
+	proj := f.AddProject("group/update-labels-errors")
+	addLabel(t, proj, "type:bug", "#000000", "")
+	addLabel(t, proj, "type:feature", "#000000", "")
+	f.FailOn("PUT", fmt.Sprintf("^projects/%d/labels$", proj.ID), http.StatusInternalServerError, 1)
+
+	name := "^type:(.+)"
+	newName := "type/${1}"
+	err := GitLabClient.Labels.UpdateWithRegex(proj.ID, &gogitlab.UpdateLabelOptions{
+		Name:    &name,
+		NewName: &newName,
+	})
+	if err == nil {
+		t.Fatal("expecting error when a label fails to update")
+	}
+	// the failed label doesn't stop the others from updating
+	var renamed int
+	for _, l := range getLabels(t, proj.ID) {
+		if l.Name == "type/bug" || l.Name == "type/feature" {
+			renamed++
+		}
+	}
+	if renamed != 1 {
+		t.Errorf("expecting 1 label to be renamed, got %d", renamed)
+	}
+}
+
+func TestLabels_Plan(t *testing.T) {
+	before(t)
+
+	proj := createProject(t, "temporary-plan-labels-", "Temporary repository to plan label changes in")
+	defer deleteProject(t, proj)
+
+	if err := GitLabClient.Labels.DeleteWithRegex(proj.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	addLabel(t, proj, "type:bug", "#000000", "A bug")
+	addLabel(t, proj, "type:feature", "#ff0000", "A feature")
+	addLabel(t, proj, "other", "#ff0000", "")
+
+	name := "^type:(.+)"
+	newName := "type/${1}"
+	col := "#ff0000"
+	changes, err := GitLabClient.Labels.PlanUpdateWithRegex(proj.ID, &gogitlab.UpdateLabelOptions{
+		Name:    &name,
+		NewName: &newName,
+		Color:   &col,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"rename 'type:bug' → 'type/bug', recolor 'type:bug' #000000 → #ff0000",
+		"rename 'type:feature' → 'type/feature'",
+	}
+	checkChanges(t, changes, expected)
+
+	changes, err = GitLabClient.Labels.PlanDeleteWithRegex(proj.ID, "^type")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChanges(t, changes, []string{"delete 'type:bug'", "delete 'type:feature'"})
+
+	// planning doesn't change anything
+	labelsExist(t, proj, []*gogitlab.Label{
+		&gogitlab.Label{Name: "type:bug", Color: "#000000"},
+		&gogitlab.Label{Name: "type:feature"},
+		&gogitlab.Label{Name: "other"},
+	})
+
+	if err := GitLabClient.Labels.ApplyChanges(proj.ID, changes); err != nil {
+		t.Fatal(err)
+	}
+	if labels := getLabels(t, proj.ID); len(labels) != 1 {
+		t.Fatalf("expecting only 'other' label after applying changes, got: %v", labels)
+	}
+}
+
 // Helper functions:
 
+func checkChanges(tb testing.TB, changes []*LabelChange, expected []string) {
+	if len(changes) != len(expected) {
+		tb.Fatalf("expecting %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+	for i, c := range changes {
+		if c.String() != expected[i] {
+			tb.Errorf("expecting change %q, got %q", expected[i], c.String())
+		}
+	}
+}
+
 func getLabels(tb testing.TB, pid interface{}) []*gogitlab.Label {
 	labels, _, err := GitLabClient.Labels.ListLabels(pid)
 	if err != nil {
@@ -0,0 +1,363 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+type MergeRequests struct {
+	*gogitlab.MergeRequestsService
+	client *Client
+}
+
+// Merge request states, for ListMergeRequestsOptions.State.
+const (
+	MergeRequestOpened = "opened"
+	MergeRequestClosed = "closed"
+	MergeRequestMerged = "merged"
+)
+
+// MergeRequest is a merge request, with the fields that
+// go-gitlab doesn't have.
+type MergeRequest struct {
+	gogitlab.MergeRequest
+	Squash bool `json:"squash"`
+	// Pipeline is the last pipeline of the source branch, nil if none.
+	Pipeline *Pipeline `json:"pipeline"`
+}
+
+// Pipeline is a CI pipeline, as merge requests refer to it.
+type Pipeline struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"` // e.g. running, success or failed
+	Ref    string `json:"ref"`
+	SHA    string `json:"sha"`
+	WebURL string `json:"web_url"`
+}
+
+// DiffStats is the size of the changes of a merge request.
+type DiffStats struct {
+	Files     int `json:"files"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// ListMergeRequestsOptions are the filters for MergeRequests.List().
+// The empty value of a field means no filter.
+type ListMergeRequestsOptions struct {
+	State        string   // MergeRequestOpened, MergeRequestClosed or MergeRequestMerged
+	Labels       []string // merge requests that have all of them
+	Milestone    string   // the title of the milestone
+	Assignee     string   // the username of the assignee
+	Search       string   // in the title and description
+	SourceBranch string
+	TargetBranch string
+}
+
+// MergeRequestOptions are the fields of a merge request to set with
+// MergeRequests.Create(). The nil fields are not set.
+type MergeRequestOptions struct {
+	// IssueOptions are the fields merge requests have in common with issues.
+	IssueOptions
+	// RemoveSourceBranch removes the source branch once merged.
+	RemoveSourceBranch *bool
+	// Squash squashes the commits into one when merged.
+	Squash *bool
+}
+
+// MergeOptions are the options of MergeRequests.Merge().
+type MergeOptions struct {
+	// WhenPipelineSucceeds merges once the running pipeline succeeds,
+	// instead of right away.
+	WhenPipelineSucceeds bool
+	// Squash squashes the commits into one.
+	Squash bool
+	// RemoveSourceBranch removes the source branch once merged.
+	RemoveSourceBranch bool
+	// Message is the message of the merge commit, "" for the default.
+	Message string
+	// SHA, if not empty, makes the merge fail if the source branch
+	// has changed since, that is its head isn't SHA anymore.
+	SHA string
+}
+
+type listMergeRequestsOptions struct {
+	State        string `url:"state,omitempty"`
+	Labels       string `url:"labels,omitempty"`
+	Milestone    string `url:"milestone,omitempty"`
+	AssigneeID   int    `url:"assignee_id,omitempty"`
+	Search       string `url:"search,omitempty"`
+	SourceBranch string `url:"source_branch,omitempty"`
+	TargetBranch string `url:"target_branch,omitempty"`
+	IID          int    `url:"iid,omitempty"` // v3
+}
+
+type mergeRequestRequest struct {
+	*issueRequest
+	SourceBranch       *string `json:"source_branch,omitempty"`
+	TargetBranch       *string `json:"target_branch,omitempty"`
+	RemoveSourceBranch *bool   `json:"remove_source_branch,omitempty"`
+	Squash             *bool   `json:"squash,omitempty"`
+}
+
+type mergeRequest struct {
+	MergeCommitMessage        string `json:"merge_commit_message,omitempty"`
+	ShouldRemoveSourceBranch  bool   `json:"should_remove_source_branch,omitempty"`
+	MergeWhenPipelineSucceeds bool   `json:"merge_when_pipeline_succeeds,omitempty"`
+	MergeWhenBuildSucceeds    bool   `json:"merge_when_build_succeeds,omitempty"` // v3
+	Squash                    bool   `json:"squash,omitempty"`
+	SHA                       string `json:"sha,omitempty"`
+}
+
+// List returns the merge requests of a project that match the options,
+// from all the pages, newest first.
+// It returns a *NotFound error if the assignee doesn't exist.
+func (srv *MergeRequests) List(pid interface{}, opts *ListMergeRequestsOptions) ([]*MergeRequest, error) {
+	if opts == nil {
+		opts = &ListMergeRequestsOptions{}
+	}
+	opt := &listMergeRequestsOptions{
+		State:        opts.State,
+		Labels:       strings.Join(opts.Labels, ","),
+		Milestone:    opts.Milestone,
+		Search:       opts.Search,
+		SourceBranch: opts.SourceBranch,
+		TargetBranch: opts.TargetBranch,
+	}
+	if opts.Assignee != "" {
+		id, err := srv.client.userID(opts.Assignee)
+		if err != nil {
+			return nil, err
+		}
+		opt.AssigneeID = id
+	}
+	return srv.list(pid, opt)
+}
+
+// Get returns the merge request of a project with the given IID, the
+// number of the merge request in the project (e.g. 12 for !12).
+// It returns a *NotFound error if the project has no such merge request.
+func (srv *MergeRequests) Get(pid interface{}, iid int) (*MergeRequest, error) {
+	if srv.client.APIVersion == APIv3 {
+		// v3 gets merge requests by their global ID
+		mrs, err := srv.list(pid, &listMergeRequestsOptions{IID: iid})
+		if err != nil {
+			return nil, err
+		}
+		if len(mrs) == 0 {
+			return nil, &NotFound{fmt.Sprintf("merge request !%d was not found", iid)}
+		}
+		return mrs[0], nil
+	}
+	mr, err := srv.do("GET", srv.path(pid, iid, ""), nil)
+	if _, ok := err.(*NotFound); ok {
+		err = &NotFound{fmt.Sprintf("merge request !%d was not found", iid)}
+	}
+	return mr, err
+}
+
+// DiffStats returns the number of files changed by the merge request
+// with the given IID, and of lines added and deleted.
+func (srv *MergeRequests) DiffStats(pid interface{}, iid int) (*DiffStats, error) {
+	path, err := srv.pathOf(pid, iid, "/changes")
+	if err != nil {
+		return nil, err
+	}
+	req, err := srv.client.NewRequest("GET", path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var changes struct {
+		Changes []struct {
+			Diff string `json:"diff"`
+		} `json:"changes"`
+	}
+	if _, err := srv.client.Do(req, &changes); err != nil {
+		return nil, err
+	}
+	stats := &DiffStats{Files: len(changes.Changes)}
+	for _, c := range changes.Changes {
+		for _, line := range strings.Split(c.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				stats.Additions++
+			case strings.HasPrefix(line, "-"):
+				stats.Deletions++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// Create creates a merge request in a project, from the source branch
+// to the target branch. The title is required.
+func (srv *MergeRequests) Create(pid interface{}, source, target string, opts *MergeRequestOptions) (*MergeRequest, error) {
+	if opts == nil || opts.Title == nil || *opts.Title == "" {
+		return nil, fmt.Errorf("the merge request has no title")
+	}
+	if source == "" || target == "" {
+		return nil, fmt.Errorf("the merge request has no source or target branch")
+	}
+	if source == target {
+		return nil, fmt.Errorf("the source and target branch are both '%s'", source)
+	}
+	req, err := srv.client.Issues.request(pid, &opts.IssueOptions)
+	if err != nil {
+		return nil, err
+	}
+	return srv.do("POST", fmt.Sprintf("projects/%s/merge_requests", projectPath(pid)), &mergeRequestRequest{
+		issueRequest:       req,
+		SourceBranch:       &source,
+		TargetBranch:       &target,
+		RemoveSourceBranch: opts.RemoveSourceBranch,
+		Squash:             opts.Squash,
+	})
+}
+
+// Close closes the merge request with the given IID, without merging it.
+func (srv *MergeRequests) Close(pid interface{}, iid int) (*MergeRequest, error) {
+	path, err := srv.pathOf(pid, iid, "")
+	if err != nil {
+		return nil, err
+	}
+	return srv.do("PUT", path, &mergeRequestRequest{
+		issueRequest: &issueRequest{StateEvent: gogitlab.String("close")},
+	})
+}
+
+// Merge merges the merge request with the given IID, or sets it to be
+// merged when its pipeline succeeds.
+func (srv *MergeRequests) Merge(pid interface{}, iid int, opts *MergeOptions) (*MergeRequest, error) {
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+	path, err := srv.pathOf(pid, iid, "/merge")
+	if err != nil {
+		return nil, err
+	}
+	opt := &mergeRequest{
+		MergeCommitMessage:       opts.Message,
+		ShouldRemoveSourceBranch: opts.RemoveSourceBranch,
+		Squash:                   opts.Squash,
+		SHA:                      opts.SHA,
+	}
+	if srv.client.APIVersion == APIv3 {
+		opt.MergeWhenBuildSucceeds = opts.WhenPipelineSucceeds
+	} else {
+		opt.MergeWhenPipelineSucceeds = opts.WhenPipelineSucceeds
+	}
+	req, err := srv.client.NewRequest("PUT", path, opt, nil)
+	if err != nil {
+		return nil, err
+	}
+	mr := new(MergeRequest)
+	resp, err := srv.client.Do(req, mr)
+	if err != nil {
+		if resp != nil {
+			switch resp.StatusCode {
+			case http.StatusNotFound:
+				return nil, &NotFound{fmt.Sprintf("merge request !%d was not found", iid)}
+			case http.StatusMethodNotAllowed, http.StatusNotAcceptable:
+				// e.g. it is closed, a draft or has conflicts
+				return nil, fmt.Errorf("merge request !%d can't be merged: %v", iid, err)
+			case http.StatusConflict:
+				return nil, fmt.Errorf("the source branch of merge request !%d has changed: %v", iid, err)
+			}
+		}
+		return nil, err
+	}
+	return mr, nil
+}
+
+// Approve approves the merge request with the given IID,
+// as the user of the client.
+func (srv *MergeRequests) Approve(pid interface{}, iid int) error {
+	return srv.approval(pid, iid, "/approve")
+}
+
+// Unapprove removes the approval of the user of the client from the
+// merge request with the given IID.
+func (srv *MergeRequests) Unapprove(pid interface{}, iid int) error {
+	return srv.approval(pid, iid, "/unapprove")
+}
+
+func (srv *MergeRequests) approval(pid interface{}, iid int, action string) error {
+	path, err := srv.pathOf(pid, iid, action)
+	if err != nil {
+		return err
+	}
+	req, err := srv.client.NewRequest("POST", path, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := srv.client.Do(req, nil)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		return &NotFound{fmt.Sprintf("merge request !%d was not found, or approvals aren't available", iid)}
+	}
+	return err
+}
+
+// pathOf returns the API path of the merge request with the given IID,
+// followed by action.
+func (srv *MergeRequests) pathOf(pid interface{}, iid int, action string) (string, error) {
+	if srv.client.APIVersion == APIv3 {
+		mr, err := srv.Get(pid, iid)
+		if err != nil {
+			return "", err
+		}
+		return srv.path(pid, mr.ID, action), nil
+	}
+	return srv.path(pid, iid, action), nil
+}
+
+// path returns the API path of the merge request with the given id, the
+// IID on v4 and the global ID on v3, followed by action.
+func (srv *MergeRequests) path(pid interface{}, id int, action string) string {
+	return fmt.Sprintf("projects/%s/merge_requests/%d%s", projectPath(pid), id, action)
+}
+
+func (srv *MergeRequests) do(method, path string, req *mergeRequestRequest) (*MergeRequest, error) {
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	r, err := srv.client.NewRequest(method, path, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	mr := new(MergeRequest)
+	resp, err := srv.client.Do(r, mr)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, &NotFound{err.Error()}
+		}
+		return nil, err
+	}
+	return mr, nil
+}
+
+// list returns the merge requests of a project from all the pages.
+func (srv *MergeRequests) list(pid interface{}, opt *listMergeRequestsOptions) ([]*MergeRequest, error) {
+	path := fmt.Sprintf("projects/%s/merge_requests", projectPath(pid))
+	var all []*MergeRequest
+	for page := 1; page > 0; {
+		req, err := srv.client.NewRequest("GET", path, opt, []gogitlab.OptionFunc{withPage(page, 100)})
+		if err != nil {
+			return nil, err
+		}
+		var mrs []*MergeRequest
+		resp, err := srv.client.Do(req, &mrs)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, &NotFound{err.Error()}
+			}
+			return nil, err
+		}
+		all = append(all, mrs...)
+		page = resp.NextPage
+	}
+	return all, nil
+}
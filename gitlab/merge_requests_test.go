@@ -0,0 +1,167 @@
+package gitlab
+
+import (
+	"testing"
+)
+
+func TestMergeRequests_List(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/mrs-list")
+	f.AddMergeRequest(proj.ID, "first fix", "bug")
+	f.AddMergeRequest(proj.ID, "second fix", "bug", "critical")
+	f.AddMergeRequest(proj.ID, "feature", "enhancement")
+
+	for _, version := range []string{APIv3, APIv4} {
+		c := f.Client(t, version)
+		type _test struct {
+			opts *ListMergeRequestsOptions
+			want []string
+		}
+		tests := []*_test{
+			&_test{nil, []string{"feature", "second fix", "first fix"}},
+			&_test{&ListMergeRequestsOptions{Labels: []string{"bug", "critical"}}, []string{"second fix"}},
+			&_test{&ListMergeRequestsOptions{Search: "first"}, []string{"first fix"}},
+			&_test{&ListMergeRequestsOptions{SourceBranch: "feature-2"}, []string{"second fix"}},
+			&_test{&ListMergeRequestsOptions{TargetBranch: "develop"}, nil},
+			&_test{&ListMergeRequestsOptions{State: MergeRequestMerged}, nil},
+		}
+		for _, test := range tests {
+			mrs, err := c.MergeRequests.List(proj.ID, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, mr := range mrs {
+				titles = append(titles, mr.Title)
+			}
+			if len(titles) != len(test.want) {
+				t.Errorf("%s: expecting %v for %+v, got %v", version, test.want, test.opts, titles)
+				continue
+			}
+			for i := range titles {
+				if titles[i] != test.want[i] {
+					t.Errorf("%s: expecting %v for %+v, got %v", version, test.want, test.opts, titles)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestMergeRequests_CreateMerge(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/mrs-create")
+
+	for _, version := range []string{APIv3, APIv4} {
+		c := f.Client(t, version)
+		title, squash := "fix crash ("+version+")", true
+		labels := []string{"bug"}
+		source := "fix-crash-" + version
+		mr, err := c.MergeRequests.Create(proj.ID, source, "master", &MergeRequestOptions{
+			IssueOptions: IssueOptions{Title: &title, Labels: &labels},
+			Squash:       &squash,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mr.Title != title || mr.SourceBranch != source || mr.TargetBranch != "master" ||
+			len(mr.Labels) != 1 || !mr.Squash {
+			t.Errorf("%s: unexpected merge request %+v", version, mr)
+		}
+		if _, err := c.MergeRequests.Create(proj.ID, source, "master", &MergeRequestOptions{
+			IssueOptions: IssueOptions{Title: &title},
+		}); err == nil {
+			t.Errorf("%s: expecting error for a second merge request of the same branch", version)
+		}
+
+		stats, err := c.MergeRequests.DiffStats(proj.ID, mr.IID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *stats != (DiffStats{Files: 1, Additions: 2, Deletions: 1}) {
+			t.Errorf("%s: unexpected diff stats %+v", version, stats)
+		}
+
+		// when the pipeline succeeds, merge_when_build_succeeds on v3
+		f.SetPipeline(proj.ID, mr.IID, "running")
+		if _, err := c.MergeRequests.Merge(proj.ID, mr.IID, &MergeOptions{SHA: "0"}); err == nil {
+			t.Errorf("%s: expecting error for a changed source branch", version)
+		}
+		if mr, err = c.MergeRequests.Merge(proj.ID, mr.IID, &MergeOptions{WhenPipelineSucceeds: true}); err != nil {
+			t.Fatal(err)
+		}
+		if mr.State != MergeRequestOpened || !mr.MergeWhenPipelineSucceeds || mr.Pipeline == nil || mr.Pipeline.Status != "running" {
+			t.Errorf("%s: expecting to be merged when the pipeline succeeds, got %+v", version, mr)
+		}
+		f.SetPipeline(proj.ID, mr.IID, "success")
+		if mr, err = c.MergeRequests.Get(proj.ID, mr.IID); err != nil || mr.State != MergeRequestMerged {
+			t.Errorf("%s: expecting the merge request to be merged, got %+v, %v", version, mr, err)
+		}
+		if _, err := c.MergeRequests.Merge(proj.ID, mr.IID, nil); err == nil {
+			t.Errorf("%s: expecting error when merging a merged merge request", version)
+		}
+
+		if _, err := c.MergeRequests.Get(proj.ID, 1000); err == nil {
+			t.Errorf("%s: expecting error for a missing merge request", version)
+		} else if _, ok := err.(*NotFound); !ok {
+			t.Errorf("%s: expecting not found, got %v", version, err)
+		}
+	}
+
+	if _, err := GitLabClient.MergeRequests.Create(proj.ID, "master", "master", &MergeRequestOptions{
+		IssueOptions: IssueOptions{Title: new(string)},
+	}); err == nil {
+		t.Error("expecting error for a merge request without a title")
+	}
+}
+
+func TestMergeRequests_DiffStats(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/mrs-diff")
+	mr := f.AddMergeRequest(proj.ID, "markdown")
+	// lines that look like the headers of a unified diff are counted too,
+	// GitLab's diffs don't have headers
+	f.SetDiff(proj.ID, mr.IID, "@@ -1,3 +1,3 @@\n--- a title\n+++ a title\n-- comment\n++ x\n-x\n+y\n context\n")
+
+	for _, version := range []string{APIv3, APIv4} {
+		c := f.Client(t, version)
+		stats, err := c.MergeRequests.DiffStats(proj.ID, mr.IID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *stats != (DiffStats{Files: 1, Additions: 3, Deletions: 3}) {
+			t.Errorf("%s: unexpected diff stats %+v", version, stats)
+		}
+	}
+}
+
+func TestMergeRequests_ApproveClose(t *testing.T) {
+	f := fake(t)
+	proj := f.AddProject("group/mrs-approve")
+	mr := f.AddMergeRequest(proj.ID, "fix")
+	c := f.Client(t, APIv4)
+
+	if err := c.MergeRequests.Approve(proj.ID, mr.IID); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Approved(proj.ID, mr.IID) {
+		t.Error("expecting the merge request to be approved")
+	}
+	if err := c.MergeRequests.Unapprove(proj.ID, mr.IID); err != nil {
+		t.Fatal(err)
+	}
+	if f.Approved(proj.ID, mr.IID) {
+		t.Error("expecting the merge request not to be approved")
+	}
+	if err := c.MergeRequests.Approve(proj.ID, 1000); err == nil {
+		t.Error("expecting error when approving a missing merge request")
+	}
+
+	closed, err := c.MergeRequests.Close(proj.ID, mr.IID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed.State != MergeRequestClosed {
+		t.Errorf("expecting the merge request to be closed, got %s", closed.State)
+	}
+}
@@ -0,0 +1,421 @@
+package gitlab
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRedirectURI is the redirect URI for the authorization code flow,
+// if the OAuthApp doesn't have one. It must be registered for the
+// application in GitLab.
+const DefaultRedirectURI = "http://127.0.0.1:7171/callback"
+
+// DefaultScopes are the scopes asked for if the OAuthApp doesn't have any.
+var DefaultScopes = []string{"api"}
+
+// tokenExpiryMargin is how long before it expires a token is refreshed,
+// so that it doesn't expire during a request.
+const tokenExpiryMargin = time.Minute
+
+// authCodeTimeout is how long AuthCodeFlow waits for the user to
+// authorize the app in the browser, replaceable in tests.
+var authCodeTimeout = 5 * time.Minute
+
+// OAuthApp is an OAuth2 application registered in GitLab (under
+// Settings > Applications), that clients log in with.
+type OAuthApp struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURI is where GitLab sends the browser back to in the
+	// authorization code flow. Must be on localhost. If empty,
+	// DefaultRedirectURI is used.
+	RedirectURI string
+	// Scopes are the scopes to ask for. If empty, DefaultScopes are used.
+	Scopes []string
+}
+
+func (app *OAuthApp) scope() string {
+	if app == nil || len(app.Scopes) == 0 {
+		return strings.Join(DefaultScopes, " ")
+	}
+	return strings.Join(app.Scopes, " ")
+}
+
+// OAuthToken is an OAuth2 access token, with the token to refresh it.
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	// ExpiresAt is nil if the token doesn't expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired returns true if the token expired, or is about to.
+func (t *OAuthToken) Expired() bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.IsZero() && !now().Add(tokenExpiryMargin).Before(*t.ExpiresAt)
+}
+
+// OAuth holds the settings for a Client that authenticates with
+// an OAuth2 token, see Options.
+type OAuth struct {
+	// App is the application the Token was issued for, used to refresh it.
+	App   *OAuthApp
+	Token *OAuthToken
+	// OnRefresh, if not nil, is called with the new token after the
+	// Token is refreshed, e.g. to save it.
+	OnRefresh func(*OAuthToken)
+}
+
+// OAuthError is an error returned by the GitLab OAuth2 endpoints.
+type OAuthError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	if e.Code != "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// tokenResponse is a token as returned by the token endpoint.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// PasswordGrant returns a token for the given user and password, using
+// the OAuth2 resource owner password credentials grant. The app can be
+// nil, for GitLab instances that allow the grant without an application.
+func PasswordGrant(uri *url.URL, app *OAuthApp, user, pass string, opts *Options) (*OAuthToken, error) {
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {user},
+		"password":   {pass},
+		"scope":      {app.scope()},
+	}
+	return requestToken(uri, app, form, opts)
+}
+
+// RefreshToken returns a new token for the given refresh token.
+func RefreshToken(uri *url.URL, app *OAuthApp, refreshToken string, opts *Options) (*OAuthToken, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	return requestToken(uri, app, form, opts)
+}
+
+// DeviceCode is the code the user enters to authorize a device, see
+// DeviceFlow.
+type DeviceCode struct {
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+	DeviceCode              string `json:"device_code"`
+}
+
+// DeviceFlow returns a token using the OAuth2 device authorization grant
+// (GitLab 17.2 and later): it calls prompt with the code the user has to
+// enter at the verification URI, then waits until the user authorizes it.
+func DeviceFlow(uri *url.URL, app *OAuthApp, opts *Options, prompt func(*DeviceCode)) (*OAuthToken, error) {
+	if app == nil || app.ClientID == "" {
+		return nil, fmt.Errorf("the device flow needs an OAuth application")
+	}
+	client, err := getOAuthClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	var code DeviceCode
+	form := url.Values{"client_id": {app.ClientID}, "scope": {app.scope()}}
+	if err := postForm(client, oauthURL(uri, "authorize_device"), form, &code); err != nil {
+		return nil, fmt.Errorf("failed to start the device flow: %v", err)
+	}
+	prompt(&code)
+
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	form = url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {code.DeviceCode},
+	}
+	for {
+		sleep(interval)
+		tok, err := requestToken(uri, app, form, opts)
+		if e, ok := err.(*OAuthError); ok {
+			switch e.Code {
+			case "authorization_pending":
+				if code.ExpiresIn <= 0 || now().Before(deadline) {
+					continue
+				}
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			}
+		}
+		return tok, err
+	}
+}
+
+// AuthCodeFlow returns a token using the OAuth2 authorization code grant
+// with PKCE: it calls open with the URL the user has to open in a browser
+// to authorize the app, and receives the code on the app's redirect URI,
+// that must be on localhost. It fails if the user doesn't authorize the
+// app within 5 minutes.
+func AuthCodeFlow(uri *url.URL, app *OAuthApp, opts *Options, open func(authURL string)) (*OAuthToken, error) {
+	if app == nil || app.ClientID == "" {
+		return nil, fmt.Errorf("the authorization code flow needs an OAuth application")
+	}
+	redirectURI := app.RedirectURI
+	if redirectURI == "" {
+		redirectURI = DefaultRedirectURI
+	}
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URI: %v", err)
+	}
+	host, _, err := net.SplitHostPort(redirect.Host)
+	if err != nil {
+		// no port
+		host = strings.Trim(redirect.Host, "[]")
+	}
+	if host != "localhost" && host != "127.0.0.1" && host != "::1" {
+		return nil, fmt.Errorf("the redirect URI must be on localhost, got '%s'", redirectURI)
+	}
+	l, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on the redirect URI: %v", err)
+	}
+	// stops the server, the response to the browser is still sent
+	defer l.Close()
+
+	state, verifier := randomToken(), randomToken()
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL := oauthURL(uri, "authorize") + "?" + url.Values{
+		"client_id":             {app.ClientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"state":                 {state},
+		"scope":                 {app.scope()},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != redirect.Path {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			res.err = fmt.Errorf("invalid state in the redirect")
+		case q.Get("error") != "":
+			res.err = &OAuthError{Code: q.Get("error"), Description: q.Get("error_description")}
+		default:
+			res.code = q.Get("code")
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Logged in, you can close this window.")
+		}
+		select {
+		case done <- res:
+		default:
+		}
+	})}
+	// don't keep the connection of the browser open once stopped
+	srv.SetKeepAlivesEnabled(false)
+	go srv.Serve(l)
+	open(authURL)
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(authCodeTimeout):
+		return nil, fmt.Errorf("timed out after %v waiting for the app to be authorized in the browser", authCodeTimeout)
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+	return requestToken(uri, app, form, opts)
+}
+
+// requestToken posts the form to the token endpoint, with the client
+// credentials of the app, if any, and returns the token.
+func requestToken(uri *url.URL, app *OAuthApp, form url.Values, opts *Options) (*OAuthToken, error) {
+	client, err := getOAuthClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	if app != nil && app.ClientID != "" {
+		form.Set("client_id", app.ClientID)
+		if app.ClientSecret != "" {
+			form.Set("client_secret", app.ClientSecret)
+		}
+	}
+	var resp tokenResponse
+	if err := postForm(client, oauthURL(uri, "token"), form, &resp); err != nil {
+		return nil, err
+	}
+	tok := &OAuthToken{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		Scope:        resp.Scope,
+	}
+	if resp.ExpiresIn > 0 {
+		expiresAt := now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+		tok.ExpiresAt = &expiresAt
+	}
+	return tok, nil
+}
+
+func postForm(client *http.Client, u string, form url.Values, v interface{}) error {
+	resp, err := client.Post(u, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		e := &OAuthError{StatusCode: resp.StatusCode}
+		json.Unmarshal(b, e)
+		return e
+	}
+	return json.Unmarshal(b, v)
+}
+
+// getOAuthClient returns the http client for the OAuth endpoints.
+func getOAuthClient(opts *Options) (*http.Client, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return getClient(opts)
+}
+
+func oauthURL(uri *url.URL, endpoint string) string {
+	u := *uri
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/oauth/" + endpoint
+	u.RawQuery = ""
+	return u.String()
+}
+
+// randomToken returns a random string for the state and the PKCE verifier.
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// oauthTransport is an http.RoundTripper that authenticates the requests
+// with an OAuth2 token instead of the private token, refreshing the token
+// when it expires or is rejected.
+type oauthTransport struct {
+	next  http.RoundTripper
+	uri   *url.URL
+	opts  *Options
+	oauth *OAuth
+	mu    sync.Mutex
+}
+
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	tok, err := t.token(nil)
+	if err != nil {
+		return nil, err
+	}
+	for refreshed := false; ; refreshed = true {
+		r := new(http.Request)
+		*r = *req
+		r.Header = make(http.Header, len(req.Header))
+		for k, v := range req.Header {
+			r.Header[k] = v
+		}
+		r.Header.Del("PRIVATE-TOKEN")
+		r.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+		if body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		resp, err := t.next.RoundTrip(r)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || refreshed || tok.RefreshToken == "" {
+			return resp, err
+		}
+		// the token may have been revoked or expired early
+		resp.Body.Close()
+		if tok, err = t.token(tok); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// token returns the current token, refreshing it if it expired or if it
+// is still rejected, the token that was rejected.
+func (t *oauthTransport) token(rejected *OAuthToken) (*OAuthToken, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tok := t.oauth.Token
+	if tok.RefreshToken == "" || (tok != rejected && !tok.Expired()) {
+		return tok, nil
+	}
+	tok, err := RefreshToken(t.uri, t.oauth.App, tok.RefreshToken, t.opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh the OAuth token, log in again: %v", err)
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = t.oauth.Token.RefreshToken
+	}
+	t.oauth.Token = tok
+	if t.oauth.OnRefresh != nil {
+		t.oauth.OnRefresh(tok)
+	}
+	return tok, nil
+}
@@ -0,0 +1,189 @@
+package gitlab
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientForUser_OAuth(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	f.OAuth = true
+	u, _ := url.Parse(f.URL)
+	proj := f.AddProject("group/oauth-user")
+
+	c, err := NewClientForUser(u, f.User, f.Password, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "" || c.OAuthToken() == nil {
+		t.Errorf("expecting an OAuth token instead of '%s'", c.Token)
+	}
+	if _, _, err := c.Client.Projects.GetProject(proj.ID); err != nil {
+		t.Error(err)
+	}
+	if u, _, err := c.Users.CurrentUser(); err != nil || u.Username != f.User {
+		t.Errorf("expecting to be logged in as '%s', got %v, %v", f.User, u, err)
+	}
+	for _, req := range f.Requests() {
+		if req == "POST session" {
+			t.Errorf("expecting no session request, got %v", f.Requests())
+		}
+	}
+
+	if _, err := NewClientForUser(u, f.User, "wrong", nil); err == nil {
+		t.Errorf("expecting error for wrong password")
+	}
+}
+
+func TestOAuth_Refresh(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	f.OAuth = true
+	f.TokenTTL = time.Hour
+	u, _ := url.Parse(f.URL)
+	proj := f.AddProject("group/oauth-refresh")
+	app := &OAuthApp{ClientID: f.ClientID}
+
+	tok, err := PasswordGrant(u, app, f.User, f.Password, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.RefreshToken == "" || tok.Expired() {
+		t.Fatalf("expecting a valid token with a refresh token, got %+v", tok)
+	}
+	var refreshed []*OAuthToken
+	c, err := NewClient(u, "", &Options{
+		APIVersion: APIv4,
+		OAuth: &OAuth{
+			App:       app,
+			Token:     tok,
+			OnRefresh: func(tok *OAuthToken) { refreshed = append(refreshed, tok) },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rejected by the server
+	f.ExpireAccessTokens()
+	if _, _, err := c.Client.Projects.GetProject(proj.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(refreshed) != 1 || c.OAuthToken() != refreshed[0] || refreshed[0].AccessToken == tok.AccessToken {
+		t.Fatalf("expecting the token to be refreshed once, got %v", refreshed)
+	}
+
+	// expired according to the client
+	expired := time.Now().Add(-time.Minute)
+	c.OAuthToken().ExpiresAt = &expired
+	if _, _, err := c.Client.Projects.GetProject(proj.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(refreshed) != 2 {
+		t.Fatalf("expecting the expired token to be refreshed, got %v", refreshed)
+	}
+
+	// the refresh token was revoked
+	c.OAuthToken().RefreshToken = "revoked"
+	f.ExpireAccessTokens()
+	if _, _, err := c.Client.Projects.GetProject(proj.ID); err == nil {
+		t.Error("expecting error when the token can't be refreshed")
+	}
+}
+
+func TestOAuth_DeviceFlow(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	f.OAuth = true
+	u, _ := url.Parse(f.URL)
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	if _, err := DeviceFlow(u, nil, nil, nil); err == nil {
+		t.Error("expecting error without an application")
+	}
+
+	var code *DeviceCode
+	tok, err := DeviceFlow(u, &OAuthApp{ClientID: f.ClientID}, nil, func(c *DeviceCode) { code = c })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code == nil || code.UserCode == "" || code.VerificationURI == "" {
+		t.Errorf("expecting a user code and verification URI, got %+v", code)
+	}
+	if tok.AccessToken == "" || tok.Scope != "api" {
+		t.Errorf("expecting an access token with the api scope, got %+v", tok)
+	}
+
+	if _, err := DeviceFlow(u, &OAuthApp{ClientID: "other"}, nil, func(*DeviceCode) {}); err == nil {
+		t.Error("expecting error for an unknown application")
+	}
+}
+
+func TestOAuth_AuthCodeFlow(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	f.OAuth = true
+	u, _ := url.Parse(f.URL)
+
+	// find a free port for the redirect URI
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	app := &OAuthApp{
+		ClientID:    f.ClientID,
+		RedirectURI: "http://" + addr + "/callback",
+		Scopes:      []string{"api", "read_user"},
+	}
+	tok, err := AuthCodeFlow(u, app, nil, func(authURL string) {
+		// the browser, that gets redirected back to the app
+		resp, err := http.Get(authURL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expecting the redirect to succeed, got %s", resp.Status)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken == "" || tok.Scope != "api read_user" {
+		t.Errorf("expecting an access token with the app scopes, got %+v", tok)
+	}
+	if l, err := net.Listen("tcp", addr); err != nil {
+		t.Errorf("expecting the redirect URI to be free after the login: %v", err)
+	} else {
+		l.Close()
+	}
+
+	// the user never authorizes the app
+	authCodeTimeout = 100 * time.Millisecond
+	defer func() { authCodeTimeout = 5 * time.Minute }()
+	if _, err := AuthCodeFlow(u, app, nil, func(string) {}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expecting a timeout, got %v", err)
+	}
+	if l, err := net.Listen("tcp", addr); err != nil {
+		t.Errorf("expecting the redirect URI to be free after the timeout: %v", err)
+	} else {
+		l.Close()
+	}
+
+	for _, uri := range []string{"http://example.com/callback", "http://example.com:7171/callback"} {
+		app.RedirectURI = uri
+		if _, err := AuthCodeFlow(u, app, nil, func(string) {}); err == nil || !strings.Contains(err.Error(), "localhost") {
+			t.Errorf("%s: expecting error for a redirect URI not on localhost, got %v", uri, err)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package gitlab
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	gogitlab "github.com/xanzy/go-gitlab"
@@ -20,25 +23,71 @@ func (e *NotFound) Error() string {
 	return e.s
 }
 
-// ProjectByPath searches and returns a project with the given path.
-// Both project and error can be nil, if no project was found.
-func (srv *Projects) ByPath(path string) (proj *gogitlab.Project, err error) {
-	path = strings.TrimPrefix(strings.TrimSuffix(path, ".git"), "/")
-	p := strings.Split(path, "/")
-	findProject := func(p *gogitlab.Project) bool {
-		if p.PathWithNamespace == path {
-			proj = p
-			return true
+// ByPath returns the project with the given path, including any
+// (nested) groups, e.g. 'group/subgroup/repo'. A numeric path is
+// treated as the project ID.
+// It returns a *NotFound error if the server has no such project.
+func (srv *Projects) ByPath(path string) (*gogitlab.Project, error) {
+	path = strings.Trim(strings.TrimSuffix(path, ".git"), "/")
+	var pid interface{} = path
+	if id, err := strconv.Atoi(path); err == nil {
+		pid = id
+	}
+	proj, resp, err := srv.GetProject(pid)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, &NotFound{fmt.Sprintf("repository with path '%s' was not found", path)}
 		}
-		return false
+		return nil, err
+	}
+	return proj, nil
+}
+
+// ByGroup returns all the projects in a group, given its path or ID,
+// including the projects in its subgroups. If there's no such group,
+// the path is considered a user namespace, e.g. 'root'.
+// It returns a *NotFound error if there's no group or user with the path.
+func (srv *Projects) ByGroup(path string) ([]*gogitlab.Project, error) {
+	path = strings.Trim(path, "/")
+	projects, err := srv.list(fmt.Sprintf("groups/%s/projects", url.QueryEscape(path)),
+		&listGroupProjectsOptions{IncludeSubgroups: true})
+	if _, ok := err.(*NotFound); ok {
+		projects, err = srv.list(fmt.Sprintf("users/%s/projects", url.QueryEscape(path)), nil)
+	}
+	if _, ok := err.(*NotFound); ok {
+		err = &NotFound{fmt.Sprintf("group with path '%s' was not found", path)}
 	}
-	err = srv.Search(p[1], &gogitlab.SearchProjectsOptions{}, findProject)
-	if err == nil && proj == nil {
-		err = &NotFound{fmt.Sprintf("repository with path '%s' was not found", path)}
+	return projects, err
+}
+
+type listGroupProjectsOptions struct {
+	IncludeSubgroups bool `url:"include_subgroups,omitempty"`
+}
+
+// list returns all the projects from all the pages of the given API list path.
+func (srv *Projects) list(path string, opt interface{}) ([]*gogitlab.Project, error) {
+	var all []*gogitlab.Project
+	for page := 1; page > 0; {
+		req, err := srv.client.NewRequest("GET", path, opt, []gogitlab.OptionFunc{withPage(page, 100)})
+		if err != nil {
+			return nil, err
+		}
+		var projects []*gogitlab.Project
+		resp, err := srv.client.Do(req, &projects)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, &NotFound{err.Error()}
+			}
+			return nil, err
+		}
+		all = append(all, projects...)
+		page = resp.NextPage
 	}
-	return
+	return all, nil
 }
 
+// Search calls stop for every project that matches the query, page by page,
+// until stop returns true or there are no more projects.
 func (srv *Projects) Search(query string, opts *gogitlab.SearchProjectsOptions, stop func(*gogitlab.Project) bool) error {
 	projects, resp, err := srv.SearchProjects(query, opts)
 	if err != nil {
@@ -49,7 +98,7 @@ func (srv *Projects) Search(query string, opts *gogitlab.SearchProjectsOptions,
 			return nil
 		}
 	}
-	if resp.NextPage > 0 && resp.NextPage < resp.LastPage {
+	if resp.NextPage > 0 {
 		opts.Page = resp.NextPage
 		return srv.Search(query, opts, stop)
 	}
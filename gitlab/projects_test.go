@@ -1,6 +1,11 @@
 package gitlab
 
-import "testing"
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
 
 func TestProjects_ByPath(t *testing.T) {
 	before(t)
@@ -13,23 +18,80 @@ func TestProjects_ByPath(t *testing.T) {
 	}
 	tests := []*_test{
 		&_test{proj.PathWithNamespace},
+		&_test{"/" + proj.PathWithNamespace + ".git"},
+		&_test{strconv.Itoa(proj.ID)},
 	}
 	for _, test := range tests {
-		proj, err := GitLabClient.Projects.ByPath(test.path)
+		p, err := GitLabClient.Projects.ByPath(test.path)
 		if err != nil {
 			t.Fatal(err)
 		}
-		if proj.PathWithNamespace != test.path {
-			t.Errorf("expecting '%s', got '%s'\n", test.path, proj.PathWithNamespace)
+		if p.ID != proj.ID {
+			t.Errorf("expecting '%s', got '%s'\n", proj.PathWithNamespace, p.PathWithNamespace)
 		}
 	}
 	tests = []*_test{
 		&_test{"root/nonexistingrepo"},
+		&_test{"root/nonexisting/nestedrepo"},
 	}
 	for _, test := range tests {
 		proj, err := GitLabClient.Projects.ByPath(test.path)
 		if _, ok := err.(*NotFound); !ok {
-			t.Fatalf("expecting not found, got: %s", proj.PathWithNamespace)
+			t.Fatalf("expecting not found, got: %v, %v", proj, err)
+		}
+	}
+}
+
+func TestProjects_ByPathNested(t *testing.T) {
+	f := fake(t)
+
+	proj := f.AddProject("group/subgroup/repo")
+	for _, version := range []string{APIv3, APIv4} {
+		p, err := f.Client(t, version).Projects.ByPath("group/subgroup/repo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.ID != proj.ID {
+			t.Errorf("%s: expecting '%s', got '%s'\n", version, proj.PathWithNamespace, p.PathWithNamespace)
 		}
 	}
+
+	f.FailOn("GET", "^projects/", http.StatusInternalServerError, 1)
+	if _, err := GitLabClient.Projects.ByPath("group/subgroup/repo"); err == nil {
+		t.Fatal("expecting error")
+	} else if _, ok := err.(*NotFound); ok {
+		t.Fatalf("expecting server error, got not found: %v", err)
+	}
+}
+
+func TestProjects_ByGroup(t *testing.T) {
+	f := fake(t)
+
+	f.AddProject("bygroup/repo1")
+	f.AddProject("bygroup/sub/repo2")
+	f.AddProject("bygroup-other/repo3")
+	for i := 0; i < 25; i++ {
+		f.AddProject(fmt.Sprintf("bygroup/many/repo-%02d", i))
+	}
+
+	projects, err := GitLabClient.Projects.ByGroup("bygroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 27 {
+		t.Errorf("expecting 27 projects in group and subgroups, got %d", len(projects))
+	}
+
+	if projects, err = GitLabClient.Projects.ByGroup("bygroup/sub"); err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].PathWithNamespace != "bygroup/sub/repo2" {
+		t.Errorf("expecting only 'bygroup/sub/repo2', got %v", projects)
+	}
+
+	if _, err := GitLabClient.Projects.ByGroup("nonexistinggroup"); err == nil {
+		t.Fatal("expecting error")
+	} else if _, ok := err.(*NotFound); !ok {
+		t.Fatalf("expecting not found, got: %v", err)
+	}
 }
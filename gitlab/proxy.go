@@ -0,0 +1,89 @@
+package gitlab
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxyFromEnvironment is http.ProxyFromEnvironment, replaceable in tests.
+var proxyFromEnvironment = http.ProxyFromEnvironment
+
+// getProxy returns the function that chooses the proxy for a request,
+// for the settings in opts: the Proxy url, or if not given the one from
+// the environment (HTTP_PROXY, HTTPS_PROXY and NO_PROXY), unless the
+// host is in NoProxy.
+func getProxy(opts *Options) (func(*http.Request) (*url.URL, error), error) {
+	proxyFor := proxyFromEnvironment
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy url '%s'", opts.Proxy)
+		}
+		proxyFor = func(*http.Request) (*url.URL, error) {
+			return proxy, nil
+		}
+	}
+	if len(opts.NoProxy) == 0 {
+		return proxyFor, nil
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if noProxy(req.URL.Host, opts.NoProxy) {
+			return nil, nil
+		}
+		return proxyFor(req)
+	}, nil
+}
+
+// noProxy returns true if host (that can include a port) matches one of
+// the entries, that are host names (matching their subdomains too), IP
+// addresses, CIDR ranges (e.g. '10.0.0.0/8') or '*' for all hosts.
+func noProxy(host string, entries []string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		switch {
+		case entry == "":
+		case entry == "*":
+			return true
+		case strings.Contains(entry, "/"):
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && ip != nil && cidr.Contains(ip) {
+				return true
+			}
+		default:
+			entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+			if host == entry || strings.HasSuffix(host, "."+entry) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headerTransport is an http.RoundTripper that adds headers to the requests.
+type headerTransport struct {
+	next   http.RoundTripper
+	header http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+len(t.header))
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	for k, v := range t.header {
+		r.Header[k] = v
+	}
+	return t.next.RoundTrip(r)
+}
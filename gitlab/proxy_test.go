@@ -0,0 +1,96 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNoProxy(t *testing.T) {
+	entries := []string{"internal.example.com", ".corp.example.com", "10.0.0.0/8", "192.168.1.1:8080"}
+	tests := []struct {
+		host    string
+		noProxy bool
+	}{
+		{"internal.example.com", true},
+		{"INTERNAL.example.com:443", true},
+		{"git.internal.example.com", true},
+		{"example.com", false},
+		{"notinternal.example.com", false},
+		{"git.corp.example.com", true},
+		{"corp.example.com", true},
+		{"10.1.2.3:8080", true},
+		{"11.1.2.3", false},
+		{"192.168.1.1", true},
+		{"gitlab.com", false},
+	}
+	for _, test := range tests {
+		if got := noProxy(test.host, entries); got != test.noProxy {
+			t.Errorf("%s: expecting no proxy %v, got %v", test.host, test.noProxy, got)
+		}
+	}
+	if !noProxy("gitlab.com", []string{"*"}) {
+		t.Error("expecting '*' to match all hosts")
+	}
+}
+
+func TestGetProxy_Environment(t *testing.T) {
+	env, err := url.Parse("http://proxy.example.com:3128")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyFromEnvironment = func(*http.Request) (*url.URL, error) { return env, nil }
+	defer func() { proxyFromEnvironment = http.ProxyFromEnvironment }()
+
+	proxyFor, err := getProxy(&Options{NoProxy: []string{"internal.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for host, want := range map[string]*url.URL{"gitlab.com": env, "git.internal.example.com": nil} {
+		req, err := http.NewRequest("GET", "https://"+host+"/api/v4/version", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := proxyFor(req); err != nil || got != want {
+			t.Errorf("%s: expecting proxy %v, got %v, %v", host, want, got, err)
+		}
+	}
+}
+
+func TestGetClient_ProxyAndHeader(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host)
+		if r.Header.Get("X-Gateway-Auth") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer proxy.Close()
+
+	client, err := getClient(&Options{
+		Proxy:   proxy.URL,
+		NoProxy: []string{"localhost"},
+		Header:  http.Header{"X-Gateway-Auth": []string{"secret"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// localhost:1 is not listening, it fails if not sent through the proxy
+	for _, u := range []string{"http://gitlab.example.com/api/v4/version", "http://localhost:1/"} {
+		resp, err := client.Get(u)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("%s: expecting 200, got %d", u, resp.StatusCode)
+			}
+		}
+	}
+	if len(proxied) != 1 || proxied[0] != "gitlab.example.com" {
+		t.Errorf("expecting only gitlab.example.com to go through the proxy, got %v", proxied)
+	}
+
+	if _, err := getClient(&Options{Proxy: "not a url"}); err == nil {
+		t.Error("expecting error for an invalid proxy url")
+	}
+}
@@ -0,0 +1,98 @@
+package gitlab
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRateLimitWait is the longest a request waits for the rate limit.
+const maxRateLimitWait = 5 * time.Minute
+
+// now and sleep are time.Now and time.Sleep, replaceable in tests.
+var (
+	now   = time.Now
+	sleep = time.Sleep
+)
+
+// rateLimit makes the requests of a Client wait when GitLab says the
+// rate limit is reached, with the RateLimit-* and Retry-After headers.
+// It is used by the retryTransport for every attempt, so it's shared
+// by all the goroutines that make requests with the Client.
+type rateLimit struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// wait sleeps until the rate limit resets, if it was reached.
+func (rl *rateLimit) wait() {
+	rl.mu.Lock()
+	d := rl.until.Sub(now())
+	rl.mu.Unlock()
+	if d > 0 {
+		sleep(d)
+	}
+}
+
+// update records when the rate limit resets, if the response says it was
+// reached.
+func (rl *rateLimit) update(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	rejected := resp.StatusCode == http.StatusTooManyRequests
+	var until time.Time
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil {
+			until = now().Add(time.Duration(secs) * time.Second)
+		} else if t, err := http.ParseTime(s); err == nil {
+			until = t
+		}
+	}
+	if until.IsZero() && (rejected || resp.Header.Get("RateLimit-Remaining") == "0") {
+		if secs, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+			until = time.Unix(secs, 0)
+		} else if rejected {
+			until = now().Add(time.Second)
+		}
+	}
+	if until.IsZero() {
+		return
+	}
+	if max := now().Add(maxRateLimitWait); until.After(max) {
+		until = max
+	}
+	rl.mu.Lock()
+	if until.After(rl.until) {
+		rl.until = until
+	}
+	rl.mu.Unlock()
+}
+
+// ForEach calls fn for every index from 0 to n-1, using at most
+// workers goroutines at a time, and returns when all calls are done.
+func ForEach(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,154 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+func TestRateLimit_Update(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	now = func() time.Time { return start }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		status int
+		header map[string]string
+		until  time.Time
+	}{
+		{200, nil, time.Time{}},
+		{200, map[string]string{"RateLimit-Remaining": "10", "RateLimit-Reset": "1500000060"}, time.Time{}},
+		{200, map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "1500000060"}, start.Add(time.Minute)},
+		{429, map[string]string{"Retry-After": "3"}, start.Add(3 * time.Second)},
+		{429, map[string]string{"RateLimit-Reset": "1500000010"}, start.Add(10 * time.Second)},
+		{429, nil, start.Add(time.Second)},
+		{429, map[string]string{"Retry-After": "86400"}, start.Add(maxRateLimitWait)},
+	}
+	for _, test := range tests {
+		resp := &http.Response{StatusCode: test.status, Header: make(http.Header)}
+		for k, v := range test.header {
+			resp.Header.Set(k, v)
+		}
+		rl := &rateLimit{}
+		rl.update(resp)
+		if !rl.until.Equal(test.until) {
+			t.Errorf("%d %v: expecting to wait until %v, got %v", test.status, test.header, test.until, rl.until)
+		}
+	}
+}
+
+func TestRateLimit_EveryAttempt(t *testing.T) {
+	f := fake(t)
+	clock := time.Unix(1500000000, 0)
+	var slept []time.Duration
+	now = func() time.Time { return clock }
+	sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+	}
+	defer func() { now, sleep = time.Now, time.Sleep }()
+
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proj := f.AddProject("group/rate-limit-attempts")
+	for _, retries := range []int{0, DefaultRetries} {
+		c, err := NewClient(u, f.Token, &Options{APIVersion: APIv4, Retries: retries})
+		if err != nil {
+			t.Fatal(err)
+		}
+		slept = nil
+		f.FailOn("POST", fmt.Sprintf("^projects/%d/labels$", proj.ID), http.StatusTooManyRequests, 1)
+		name := fmt.Sprintf("limited-%d", retries)
+		color := "#000000"
+		_, _, err = c.Labels.CreateLabel(proj.ID, &gogitlab.CreateLabelOptions{Name: &name, Color: &color})
+		if (err == nil) != (retries > 0) {
+			t.Errorf("%d retries: expecting the 429 to be retried only with retries, got %v", retries, err)
+		}
+		// the 429 is recorded for the other requests, even if retried
+		if limit := c.http.Transport.(*retryTransport).limit; limit.until.IsZero() {
+			t.Errorf("%d retries: expecting the rate limit to be recorded", retries)
+		}
+		if retries == 0 {
+			// the next request waits for the rate limit
+			if _, _, err := c.Labels.ListLabels(proj.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// the fake sends Retry-After: 1
+		if len(slept) != 1 || slept[0] != time.Second {
+			t.Errorf("%d retries: expecting to wait 1s for the rate limit, got %v", retries, slept)
+		}
+	}
+}
+
+func TestLabels_ApplyChangesConcurrently(t *testing.T) {
+	f := fake(t)
+	var (
+		mu    sync.Mutex
+		slept []time.Duration
+	)
+	sleep = func(d time.Duration) {
+		mu.Lock()
+		slept = append(slept, d)
+		mu.Unlock()
+	}
+	defer func() { sleep = time.Sleep }()
+
+	proj := f.AddProject("group/apply-changes-concurrently")
+	var changes []*LabelChange
+	for i := 0; i < 20; i++ {
+		changes = append(changes, &LabelChange{New: &gogitlab.Label{
+			Name:  fmt.Sprintf("label-%02d", i),
+			Color: "#000000",
+		}})
+	}
+	labelsPath := fmt.Sprintf("^projects/%d/labels$", proj.ID)
+	f.FailOn("POST", labelsPath, http.StatusTooManyRequests, 3)
+
+	// the requests rejected with 429 are retried by the transport
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(u, f.Token, &Options{APIVersion: APIv4, Retries: DefaultRetries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Labels.ApplyChanges(proj.ID, changes); err != nil {
+		t.Fatal(err)
+	}
+	// 20 labels plus the global ones
+	if labels := getLabels(t, proj.ID); len(labels) != 22 {
+		t.Errorf("expecting 22 labels, got %d", len(labels))
+	}
+	if len(slept) == 0 {
+		t.Error("expecting to wait for the rate limit")
+	}
+
+	// errors are listed in the order of the changes
+	for _, c := range changes {
+		c.Old, c.New = c.New, nil
+	}
+	f.FailOn("DELETE", labelsPath, http.StatusInternalServerError, 0)
+	err = c.Labels.ApplyChanges(proj.ID, changes)
+	if err == nil {
+		t.Fatal("expecting error when deleting fails")
+	}
+	lines := strings.Split(err.Error(), "\n")[1:]
+	if len(lines) != len(changes) {
+		t.Fatalf("expecting %d errors, got: %v", len(changes), err)
+	}
+	for i, line := range lines {
+		if prefix := fmt.Sprintf("'label-%02d' failed to delete:", i); !strings.HasPrefix(line, prefix) {
+			t.Errorf("expecting error %d to start with %q, got %q", i, prefix, line)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gitlab
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// getTLSConfig returns the TLS configuration for the settings in opts.
+func getTLSConfig(opts *Options) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: opts.Insecure}
+	if opts.CAFile != "" {
+		pem, err := ioutil.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in the CA file '%s'", opts.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, fmt.Errorf("both the client certificate and key files are needed")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// CertificateError is returned when the TLS certificate of the
+// GitLab server fails to verify.
+type CertificateError struct {
+	Host string
+	Err  error
+}
+
+func (e *CertificateError) Error() string {
+	return fmt.Sprintf("the TLS certificate of %s failed to verify: %v "+
+		"(if the server uses a private CA, set the CA file to its certificate; "+
+		"to not verify the certificate, which is insecure, use the insecure setting)",
+		e.Host, e.Err)
+}
+
+// certErrorTransport is an http.RoundTripper that returns a
+// *CertificateError when the server's certificate fails to verify.
+type certErrorTransport struct {
+	next http.RoundTripper
+}
+
+func (t *certErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil && isCertificateError(err) {
+		return nil, &CertificateError{Host: req.URL.Host, Err: err}
+	}
+	return resp, err
+}
+
+// isCertificateError returns true if err, or an error it wraps, says
+// that the server's certificate failed to verify.
+func isCertificateError(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError, x509.SystemRootsError:
+			return true
+		case *url.Error:
+			err = e.Err
+		case interface {
+			Unwrap() error
+		}:
+			// newer Go versions wrap the errors above, e.g. in a
+			// *tls.CertificateVerificationError
+			err = e.Unwrap()
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package gitlab
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetClient_TLS(t *testing.T) {
+	var clientCerts int
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientCerts = len(r.TLS.PeerCertificates)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "gitlab-cli-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// the server's certificate is self-signed, so it is its own CA
+	cert := srv.TLS.Certificates[0]
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writePEM(t, certFile, "CERTIFICATE", cert.Certificate[0])
+	writePEM(t, keyFile, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(cert.PrivateKey.(*rsa.PrivateKey)))
+
+	get := func(opts *Options) error {
+		client, err := getClient(opts)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	err = get(&Options{})
+	if err == nil || !strings.Contains(err.Error(), "failed to verify") {
+		t.Errorf("expecting a certificate error by default, got %v", err)
+	}
+	if err := get(&Options{Insecure: true}); err != nil {
+		t.Errorf("insecure: %v", err)
+	}
+	if err := get(&Options{CAFile: certFile}); err != nil {
+		t.Errorf("CA file: %v", err)
+	}
+	if clientCerts != 0 {
+		t.Errorf("expecting no client certificate, got %d", clientCerts)
+	}
+	if err := get(&Options{CAFile: certFile, CertFile: certFile, KeyFile: keyFile}); err != nil {
+		t.Errorf("client certificate: %v", err)
+	}
+	if clientCerts != 1 {
+		t.Errorf("expecting the client certificate, got %d", clientCerts)
+	}
+
+	if _, err := getClient(&Options{CAFile: keyFile}); err == nil {
+		t.Error("expecting error for a CA file without certificates")
+	}
+	if _, err := getClient(&Options{CertFile: certFile}); err == nil {
+		t.Error("expecting error for a client certificate without key")
+	}
+}
+
+func TestIsCertificateError(t *testing.T) {
+	unknown := x509.UnknownAuthorityError{}
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{unknown, true},
+		{x509.HostnameError{Host: "gitlab.example.com"}, true},
+		{&url.Error{Op: "Get", URL: "https://gitlab.example.com", Err: unknown}, true},
+		{&wrappedError{unknown}, true},
+		{&url.Error{Op: "Get", URL: "https://gitlab.example.com", Err: &wrappedError{unknown}}, true},
+		{&wrappedError{x509.CertificateInvalidError{Reason: x509.Expired}}, true},
+		{errors.New("x509: not a certificate error, just the same words"), false},
+		{io.ErrUnexpectedEOF, false},
+	}
+	for _, test := range tests {
+		if got := isCertificateError(test.err); got != test.want {
+			t.Errorf("%v: expecting %v, got %v", test.err, test.want, got)
+		}
+	}
+}
+
+// wrappedError wraps an error like the *tls.CertificateVerificationError
+// of newer Go versions.
+type wrappedError struct {
+	err error
+}
+
+func (e *wrappedError) Error() string { return "tls: " + e.err.Error() }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+func writePEM(tb testing.TB, file, typ string, b []byte) {
+	if err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: b}), 0600); err != nil {
+		tb.Fatal(err)
+	}
+}
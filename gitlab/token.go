@@ -0,0 +1,107 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+// TokenInfo is what GitLab knows about the token a Client uses.
+type TokenInfo struct {
+	// Type is "oauth" for OAuth2 tokens, "private" otherwise.
+	Type string `json:"type"`
+	// Name is the name of a personal access token.
+	Name string `json:"name,omitempty"`
+	// Scopes are nil if unknown, e.g. for GitLab versions older than
+	// 15.5 that can't tell the scopes of a personal access token.
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is nil if the token doesn't expire or it's unknown.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// personalAccessToken is a token as returned by personal_access_tokens/self.
+type personalAccessToken struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+// oauthTokenInfo is a token as returned by oauth/token/info.
+type oauthTokenInfo struct {
+	Scope            []string `json:"scope"`
+	ExpiresInSeconds *int64   `json:"expires_in_seconds"`
+}
+
+// TokenInfo returns the scopes and expiry of the token of the client.
+func (c *Client) TokenInfo() (*TokenInfo, error) {
+	if c.oauth != nil {
+		return c.oauthTokenInfo()
+	}
+	info := &TokenInfo{Type: "private"}
+	if c.APIVersion == APIv3 {
+		return info, nil
+	}
+	req, err := c.Client.NewRequest("GET", "personal_access_tokens/self", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var pat personalAccessToken
+	resp, err := c.Client.Do(req, &pat)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// older GitLab, or not a personal access token
+		return info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info.Name = pat.Name
+	info.Scopes = pat.Scopes
+	if pat.ExpiresAt != "" {
+		t, err := time.Parse("2006-01-02", pat.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token expiry '%s': %v", pat.ExpiresAt, err)
+		}
+		info.ExpiresAt = &t
+	}
+	return info, nil
+}
+
+func (c *Client) oauthTokenInfo() (*TokenInfo, error) {
+	info := &TokenInfo{Type: "oauth"}
+	resp, err := c.http.Get(oauthURL(c.uri, "token/info"))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := gogitlab.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	var ti oauthTokenInfo
+	if err := json.NewDecoder(resp.Body).Decode(&ti); err != nil {
+		return nil, err
+	}
+	info.Scopes = ti.Scope
+	if ti.ExpiresInSeconds != nil {
+		t := now().Add(time.Duration(*ti.ExpiresInSeconds) * time.Second)
+		info.ExpiresAt = &t
+	}
+	return info, nil
+}
+
+// HasScope returns true if the token has the scope, or if its scopes
+// are unknown.
+func (info *TokenInfo) HasScope(scope string) bool {
+	if info.Scopes == nil {
+		return true
+	}
+	for _, s := range info.Scopes {
+		if strings.EqualFold(s, scope) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package gitlab
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_TokenInfo(t *testing.T) {
+	f := newFakeGitLab()
+	defer f.Close()
+	u, _ := url.Parse(f.URL)
+
+	info, err := f.Client(t, APIv4).TokenInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != "private" || !reflect.DeepEqual(info.Scopes, []string{"api"}) || info.ExpiresAt != nil {
+		t.Errorf("expecting a private token with the api scope that doesn't expire, got %+v", info)
+	}
+	if !info.HasScope("api") || info.HasScope("sudo") {
+		t.Errorf("expecting only the api scope, got %v", info.Scopes)
+	}
+
+	f.TokenExpiresAt = "2030-01-02"
+	if info, err = f.Client(t, APIv4).TokenInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if info.ExpiresAt == nil || !info.ExpiresAt.Equal(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expecting the token to expire on 2030-01-02, got %v", info.ExpiresAt)
+	}
+
+	// unknown scopes on v3
+	if info, err = f.Client(t, APIv3).TokenInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if info.Scopes != nil || !info.HasScope("sudo") {
+		t.Errorf("expecting unknown scopes on %s, got %v", APIv3, info.Scopes)
+	}
+
+	f.OAuth = true
+	f.TokenTTL = time.Hour
+	app := &OAuthApp{ClientID: f.ClientID, Scopes: []string{"api", "read_user"}}
+	tok, err := PasswordGrant(u, app, f.User, f.Password, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(u, "", &Options{OAuth: &OAuth{App: app, Token: tok}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err = c.TokenInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != "oauth" || !reflect.DeepEqual(info.Scopes, app.Scopes) {
+		t.Errorf("expecting an OAuth token with scopes %v, got %+v", app.Scopes, info)
+	}
+	if info.ExpiresAt == nil || info.ExpiresAt.Sub(time.Now()) < 59*time.Minute {
+		t.Errorf("expecting the token to expire in an hour, got %v", info.ExpiresAt)
+	}
+}
@@ -0,0 +1,117 @@
+package gitlab
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults for the retries of the requests, see Options.
+const (
+	DefaultRetries      = 3
+	DefaultRetryBackoff = 500 * time.Millisecond
+)
+
+// maxRetryBackoff is the longest to wait before a retry.
+const maxRetryBackoff = 30 * time.Second
+
+// retryTransport is an http.RoundTripper that retries the requests that
+// fail with a network error, 429 Too Many Requests or a 5xx status,
+// waiting longer before every retry (exponential backoff with jitter)
+// or as long as the Retry-After header says. Every attempt waits for
+// the rate limit, and records it if the response says it was reached,
+// so that the other requests wait too; after a 429 that's the wait
+// before the retry.
+//
+// Only idempotent requests are retried after network errors and 5xx
+// responses, since the server may have already made the change. All
+// requests are retried after 429, that GitLab sends before doing anything.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+	limit   *rateLimit
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		r := new(http.Request)
+		*r = *req
+		if body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		t.limit.wait()
+		resp, err := t.next.RoundTrip(r)
+		t.limit.update(resp)
+		if attempt >= t.retries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		var wait time.Duration
+		if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+			wait = t.wait(attempt, resp)
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if wait > 0 {
+			sleep(wait)
+		}
+	}
+}
+
+// shouldRetry returns true if the request can be retried after
+// getting resp or err.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if _, ok := err.(*CertificateError); ok {
+		return false
+	}
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		return false
+	}
+	return err != nil || resp.StatusCode >= 500
+}
+
+// wait returns how long to wait before retrying, after the
+// given attempt (0 for the first one).
+func (t *retryTransport) wait(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			if d := time.Duration(secs) * time.Second; d < maxRetryBackoff {
+				return d
+			}
+			return maxRetryBackoff
+		}
+	}
+	d := t.backoff
+	for i := 0; i < attempt && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// wait between half and all of it, so that many clients
+	// retrying at once don't all hit the server at the same time
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
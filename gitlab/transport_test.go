@@ -0,0 +1,92 @@
+package gitlab
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	clock := time.Unix(1500000000, 0)
+	var slept []time.Duration
+	now = func() time.Time { return clock }
+	sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+	}
+	defer func() { now, sleep = time.Now, time.Sleep }()
+
+	var (
+		attempts int
+		status   []int
+		bodies   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		st := http.StatusOK
+		if attempts < len(status) {
+			st = status[attempts]
+		}
+		attempts++
+		if st == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "2")
+		}
+		w.WriteHeader(st)
+	}))
+	defer srv.Close()
+
+	client, err := getClient(&Options{Retries: 3, RetryBackoff: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method   string
+		status   []int
+		attempts int
+		result   int
+	}{
+		{"GET", nil, 1, 200},
+		{"GET", []int{502, 503}, 3, 200},
+		{"PUT", []int{500, 500, 500, 500, 500}, 4, 500},
+		{"DELETE", []int{404}, 1, 404},
+		{"POST", []int{503}, 1, 503},
+		{"POST", []int{429, 429}, 3, 200},
+	}
+	for _, test := range tests {
+		attempts, status, bodies, slept = 0, test.status, nil, nil
+		req, err := http.NewRequest(test.method, srv.URL, strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.result || attempts != test.attempts {
+			t.Errorf("%s %v: expecting %d after %d attempts, got %d after %d",
+				test.method, test.status, test.result, test.attempts, resp.StatusCode, attempts)
+		}
+		for _, b := range bodies {
+			if b != "body" {
+				t.Errorf("%s %v: expecting the body in every attempt, got %q", test.method, test.status, b)
+			}
+		}
+		if len(slept) != attempts-1 {
+			t.Errorf("%s %v: expecting %d waits, got %v", test.method, test.status, attempts-1, slept)
+		}
+		for i, d := range slept {
+			min, max := time.Second<<uint(i)/2, time.Second<<uint(i)
+			if test.status[i] == http.StatusTooManyRequests {
+				min, max = 2*time.Second, 2*time.Second
+			}
+			if d < min || d > max {
+				t.Errorf("%s %v: expecting wait %d between %v and %v, got %v", test.method, test.status, i, min, max, d)
+			}
+		}
+	}
+}
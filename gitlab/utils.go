@@ -1,8 +1,16 @@
 package gitlab
 
 import (
+	"fmt"
 	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
+
+	gogitlab "github.com/xanzy/go-gitlab"
 )
 
 // RandomString generates a random string of a specified length.
@@ -15,3 +23,40 @@ func RandomString(strlen int) string {
 	}
 	return string(result)
 }
+
+// withPage returns a request option that asks for the given page
+// of a paginated list.
+func withPage(page, perPage int) gogitlab.OptionFunc {
+	return func(req *http.Request) error {
+		q := req.URL.Query()
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(perPage))
+		req.URL.RawQuery = q.Encode()
+		return nil
+	}
+}
+
+// exit is os.Exit, replaceable in tests.
+var exit = os.Exit
+
+// cleanupOnSignal calls cleanup and exits if the process receives an
+// interrupt or termination signal, until the returned stop function
+// is called.
+func cleanupOnSignal(cleanup func()) (stop func()) {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case s := <-sig:
+			fmt.Fprintf(os.Stderr, "%v, cleaning up...\n", s)
+			cleanup()
+			exit(1)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
@@ -0,0 +1,37 @@
+package gitlab
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCleanupOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("can't send interrupt signals on windows")
+	}
+	exited := make(chan int, 1)
+	exit = func(code int) { exited <- code }
+	defer func() { exit = os.Exit }()
+
+	cleaned := false
+	stop := cleanupOnSignal(func() { cleaned = true })
+	defer stop()
+
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case code := <-exited:
+		if !cleaned {
+			t.Error("exited without cleaning up")
+		}
+		if code == 0 {
+			t.Error("expecting non-zero exit code")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the cleanup")
+	}
+}